@@ -1,32 +1,147 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"io"
 	"log"
 	"log/slog"
-	"net/http"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/hesampakdaman/banking-service/internal/adapters/exchange"
 	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter"
+	"github.com/hesampakdaman/banking-service/internal/adapters/jwt"
 	"github.com/hesampakdaman/banking-service/internal/adapters/storage"
+	"github.com/hesampakdaman/banking-service/internal/config"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 	"github.com/hesampakdaman/banking-service/internal/service"
 )
 
 func main() {
-	logger := slog.New(slog.NewTextHandler(log.Writer(), nil))
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run starts the service and serves requests until it receives SIGINT or
+// SIGTERM. It then stops accepting connections, drains the requests in
+// flight and closes the repository before returning.
+func run() error {
+	// Load settings from the config file, environment and flags, in
+	// increasing precedence
+	cfg, opts, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if opts.PrintConfig {
+		return cfg.Print(os.Stdout)
+	}
 
-	// Initialize repository & service layer
-	repo := storage.NewMemoryRepository()
-	bankService := service.NewBankService(repo, logger)
+	logger := cfg.Log.NewLogger(log.Writer())
+
+	// Initialize exchange rates; without a rates file only same-currency
+	// transfers are possible
+	rates := exchange.NewStaticRateProvider()
+	if cfg.ExchangeRatesFile != "" {
+		if rates, err = exchange.NewFileRateProvider(cfg.ExchangeRatesFile); err != nil {
+			return err
+		}
+	}
+
+	// Initialize repository & service layer; state only survives restarts
+	// with the file or SQLite backend
+	repo, err := openRepository(cfg.Storage, logger)
+	if err != nil {
+		return err
+	}
+	defer closeRepository(repo, logger)
+
+	// Transfers above the threshold are held for a second operator's
+	// approval
+	bankService := service.NewBankService(repo, rates, domain.ApprovalThreshold{
+		Amount:   cfg.Limits.ApprovalThreshold,
+		Currency: cfg.Limits.ApprovalCurrency,
+	}, logger)
+
+	// Register the bootstrap admin key, with which further keys are issued
+	if token := cfg.Auth.AdminAPIKey; token != "" {
+		_, err := bankService.RegisterAPIKey(context.Background(), token, "admin", domain.RoleAdmin)
+		if err != nil && !errors.Is(err, domain.ErrAPIKeyAlreadyExists) {
+			return err
+		}
+	}
+
+	// Accept bearer tokens when a key set is configured
+	var bearerTokens httpadapter.Authenticator
+	if cfg.Auth.JWKSFile != "" {
+		verifier, err := jwt.NewFileVerifier(cfg.Auth.JWKSFile, cfg.Auth.JWTAudience, logger)
+		if err != nil {
+			return err
+		}
+		bearerTokens = verifier
+	}
 
 	// Initialize http server
 	mux := httpadapter.NewRouter(bankService)
-	loggedMux := httpadapter.LoggingMiddleware(mux, logger)
-	server := &http.Server{
-		Addr:    ":8080",
-		Handler: loggedMux,
+	idempotentMux := httpadapter.IdempotencyMiddleware(mux, httpadapter.NewIdempotencyStore(cfg.Timeouts.IdempotencyWindow.Duration))
+	loggedMux := httpadapter.LoggingMiddleware(idempotentMux, logger)
+	authenticatedMux := httpadapter.AuthenticationMiddleware(loggedMux, bankService, bearerTokens, logger)
+	timedMux := httpadapter.TimeoutMiddleware(authenticatedMux, cfg.Timeouts.Request.Duration)
+	server := httpadapter.NewServer(cfg.ListenAddr, httpadapter.RequestIDMiddleware(timedMux), httpadapter.ServerTimeouts{
+		ReadHeader: cfg.Timeouts.ReadHeader.Duration,
+		Read:       cfg.Timeouts.Read.Duration,
+		Write:      cfg.Timeouts.Write.Duration,
+		Idle:       cfg.Timeouts.Idle.Duration,
+	}, logger)
+
+	ln, err := net.Listen("tcp", cfg.ListenAddr)
+	if err != nil {
+		return err
 	}
 
-	logger.Info("Starting banking-service on :8080")
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatal(err)
+	// Deploys stop the service with SIGTERM; requests in flight are allowed
+	// to complete. A second signal stops it at once.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	logger.Info("Starting banking-service", "addr", ln.Addr().String(), "storage", cfg.Storage.Backend)
+	return httpadapter.Serve(ctx, server, ln, cfg.Timeouts.Shutdown.Duration, logger)
+}
+
+// openRepository opens the configured storage backend.
+func openRepository(cfg config.Storage, logger *slog.Logger) (ports.Repository, error) {
+	switch cfg.Backend {
+	case config.StorageSQLite:
+		return storage.NewSQLiteRepository(cfg.Path, logger)
+	case config.StorageFile:
+		return storage.NewFileRepository(cfg.Path, cfg.SnapshotInterval, logger)
+	default:
+		return storage.NewMemoryRepository(), nil
+	}
+}
+
+// closeRepository closes repo, if it holds resources, so that everything
+// it was given is on disk.
+func closeRepository(repo ports.Repository, logger *slog.Logger) {
+	closer, ok := repo.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		logger.Error("Failed to close repository", "error", err.Error())
+		return
 	}
+	logger.Info("Repository closed")
 }
@@ -0,0 +1,64 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+type currencyPair struct {
+	from, to domain.Currency
+}
+
+// StaticRateProvider serves a fixed set of exchange rates. It is meant for
+// tests and for deployments that load rates from a file.
+type StaticRateProvider struct {
+	rates map[currencyPair]domain.ExchangeRate
+}
+
+func NewStaticRateProvider(rates ...domain.ExchangeRate) ports.ExchangeRateProvider {
+	provider := &StaticRateProvider{rates: make(map[currencyPair]domain.ExchangeRate, len(rates))}
+	for _, rate := range rates {
+		provider.rates[currencyPair{rate.From, rate.To}] = rate
+	}
+	return provider
+}
+
+// NewFileRateProvider loads rates from a JSON file containing an array of
+// objects with "from", "to", "rate" and "timestamp" fields, e.g.
+//
+//	[{"from": "USD", "to": "EUR", "rate": "0.9215", "timestamp": "2025-02-12T12:00:00Z"}]
+func NewFileRateProvider(path string) (ports.ExchangeRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading exchange rates: %w", err)
+	}
+
+	var entries []domain.ExchangeRate
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decoding exchange rates: %w", err)
+	}
+
+	rates := make([]domain.ExchangeRate, 0, len(entries))
+	for _, entry := range entries {
+		rate, err := domain.NewExchangeRate(entry.From, entry.To, entry.Rate, entry.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("exchange rate %s/%s: %w", entry.From, entry.To, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return NewStaticRateProvider(rates...), nil
+}
+
+func (p *StaticRateProvider) Rate(ctx context.Context, from, to domain.Currency) (domain.ExchangeRate, error) {
+	rate, exists := p.rates[currencyPair{from, to}]
+	if !exists {
+		return domain.ExchangeRate{}, domain.ErrExchangeRateUnavailable
+	}
+	return rate, nil
+}
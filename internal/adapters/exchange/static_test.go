@@ -0,0 +1,67 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+func TestStaticRateProvider_Rate(t *testing.T) {
+	ctx := context.Background()
+
+	// Given: A provider with a single USD→EUR quote
+	expected, _ := domain.NewExchangeRate("USD", "EUR", domain.MustParseRate("0.92"), time.Now())
+	provider := NewStaticRateProvider(expected)
+
+	// When: Looking up the quoted pair
+	actual, err := provider.Rate(ctx, "USD", "EUR")
+
+	// Then: The quote should be returned
+	assert.NilError(t, err)
+	assert.DeepEqual(t, expected, actual)
+
+	// And: The inverse pair is not derived implicitly
+	_, err = provider.Rate(ctx, "EUR", "USD")
+	assert.Assert(t, errors.Is(err, domain.ErrExchangeRateUnavailable))
+}
+
+func TestFileRateProvider(t *testing.T) {
+	ctx := context.Background()
+
+	// Given: A rates file
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`[
+		{"from": "USD", "to": "EUR", "rate": "0.9215", "timestamp": "2025-02-12T12:00:00Z"}
+	]`), 0o600)
+	assert.NilError(t, err)
+
+	// When: Loading it
+	provider, err := NewFileRateProvider(path)
+	assert.NilError(t, err)
+
+	// Then: The rate and its timestamp are available
+	rate, err := provider.Rate(ctx, "USD", "EUR")
+	assert.NilError(t, err)
+	assert.Equal(t, rate.Rate.String(), "0.9215")
+	assert.Equal(t, rate.Timestamp, time.Date(2025, 2, 12, 12, 0, 0, 0, time.UTC))
+}
+
+func TestFileRateProvider_InvalidRate(t *testing.T) {
+	// Given: A rates file with a non-positive rate
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`[{"from": "USD", "to": "EUR", "rate": "0"}]`), 0o600)
+	assert.NilError(t, err)
+
+	// When: Loading it
+	_, err = NewFileRateProvider(path)
+
+	// Then: It should be rejected
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidExchangeRate))
+}
@@ -0,0 +1,30 @@
+// Package auth carries the authenticated principal of the HTTP request
+// being handled, so that handlers can authorize it and it can be logged.
+package auth
+
+import (
+	"context"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// APIKeyHeader is the request header carrying an API key.
+const APIKeyHeader = "X-API-Key"
+
+// Challenge is the WWW-Authenticate header of responses to requests that
+// lack valid credentials: an API key, or a bearer token in the
+// Authorization header.
+const Challenge = `APIKey realm="banking-service", Bearer realm="banking-service"`
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying principal.
+func NewContext(ctx context.Context, principal domain.Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns the principal carried by ctx, if any.
+func FromContext(ctx context.Context) (domain.Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(domain.Principal)
+	return principal, ok
+}
@@ -0,0 +1,93 @@
+package httpadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/handlers"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/requestid"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// Authenticator resolves a credential, such as an API key or a bearer
+// token, to the principal it was issued to.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (domain.Principal, error)
+}
+
+// AuthenticationMiddleware attaches the principal identified by the
+// X-API-Key header, or by the bearer token in the Authorization header, to
+// the request context. API keys are resolved by apiKeys and bearer tokens
+// by bearerTokens; if bearerTokens is nil, bearer tokens are not accepted.
+//
+// A request with credentials that are not recognised, or with both kinds
+// at once, is rejected with 401. A request without credentials is passed
+// on anonymously; every route but the public ones rejects it.
+func AuthenticationMiddleware(next http.Handler, apiKeys, bearerTokens Authenticator, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticator, token, err := credentials(r, apiKeys, bearerTokens)
+		if err == nil && token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var principal domain.Principal
+		if err == nil {
+			principal, err = authenticator.Authenticate(r.Context(), token)
+		}
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			logger.WarnContext(r.Context(), "Rejected request with invalid credentials",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"remote_addr", r.RemoteAddr,
+				"request_id", requestid.FromContext(r.Context()),
+				"reason", err.Error(),
+			)
+		}
+		if err != nil {
+			handlers.WriteError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	})
+}
+
+// credentials returns the credential sent with r and the authenticator
+// that resolves it, or an empty token if r carries none.
+func credentials(r *http.Request, apiKeys, bearerTokens Authenticator) (Authenticator, string, error) {
+	apiKey := r.Header.Get(auth.APIKeyHeader)
+	authorization := r.Header.Get("Authorization")
+
+	switch {
+	case authorization == "":
+		return apiKeys, apiKey, nil
+	case apiKey != "":
+		return nil, "", fmt.Errorf("%w: both an API key and an Authorization header were sent", domain.ErrUnauthenticated)
+	}
+
+	scheme, token, _ := strings.Cut(authorization, " ")
+	switch {
+	case !strings.EqualFold(scheme, "Bearer") || token == "":
+		return nil, "", fmt.Errorf("%w: unsupported Authorization scheme", domain.ErrUnauthenticated)
+	case bearerTokens == nil:
+		return nil, "", fmt.Errorf("%w: bearer tokens are not accepted", domain.ErrUnauthenticated)
+	}
+	return bearerTokens, token, nil
+}
+
+// requireAuthentication rejects anonymous requests with 401.
+func requireAuthentication(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.FromContext(r.Context()); !ok {
+			handlers.WriteError(w, r, domain.ErrUnauthenticated)
+			return
+		}
+		next(w, r)
+	}
+}
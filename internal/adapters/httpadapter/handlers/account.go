@@ -1,24 +1,43 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 )
 
 func (h *httpHandler) CreateAccountHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Owner          string  `json:"owner"`
-		InitialBalance float64 `json:"initial_balance"`
+		Owner          string          `json:"owner" required:"true"`
+		Currency       domain.Currency `json:"currency" required:"true"`
+		InitialBalance domain.Money    `json:"initial_balance" required:"true"`
+		OverdraftLimit domain.Money    `json:"overdraft_limit"`
+	}
+
+	if err := decodeJSON(w, r, &req); err != nil {
+		WriteError(w, r, err)
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+	// Customers may only open empty accounts in their own name; funding
+	// an account and granting overdraft are up to operators
+	principal, err := principalOf(r.Context())
+	if err == nil && !principal.IsOperator() &&
+		(req.Owner != principal.Subject || !req.InitialBalance.IsZero() || !req.OverdraftLimit.IsZero()) {
+		err = domain.ErrForbidden
+	}
+	if err != nil {
+		WriteError(w, r, err)
 		return
 	}
 
-	accountID, err := h.service.CreateAccount(r.Context(), req.Owner, req.InitialBalance)
+	accountID, err := h.service.CreateAccount(r.Context(), req.Owner, req.Currency, req.InitialBalance, req.OverdraftLimit)
 	if err != nil {
-		http.Error(w, err.Error(), domainErrToStatusCode(err))
+		WriteError(w, r, err)
 		return
 	}
 
@@ -33,9 +52,18 @@ func (h *httpHandler) CreateAccountHandler(w http.ResponseWriter, r *http.Reques
 func (h *httpHandler) GetAccountHandler(w http.ResponseWriter, r *http.Request) {
 	accountID := r.PathValue("id")
 
+	principal, err := principalOf(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
 	account, err := h.service.GetAccount(r.Context(), accountID)
+	if err == nil && !principal.CanAccess(account) {
+		err = domain.ErrForbidden
+	}
 	if err != nil {
-		http.Error(w, err.Error(), domainErrToStatusCode(err))
+		WriteError(w, r, err)
 		return
 	}
 
@@ -47,11 +75,151 @@ func (h *httpHandler) GetAccountHandler(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *httpHandler) ListAccountsHandler(w http.ResponseWriter, r *http.Request) {
-	accounts := h.service.ListAccounts(r.Context())
+	principal, err := principalOf(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	query, err := parseAccountQuery(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	// Customers only see the accounts they may access
+	query.VisibleTo = &principal
+
+	page, err := h.service.QueryAccounts(r.Context(), query)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := struct {
+		Accounts   []domain.Account `json:"accounts"`
+		NextCursor string           `json:"next_cursor,omitempty"`
+		Total      int              `json:"total"`
+	}{page.Accounts, page.NextCursor, page.Total}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// parseAccountQuery reads the filters and paging parameters of
+// ListAccountsHandler: owner, status, min_balance, max_balance, cursor and
+// limit. Absent parameters are left unset.
+func parseAccountQuery(params url.Values) (ports.AccountQuery, error) {
+	query := ports.AccountQuery{
+		OwnerPrefix: params.Get("owner"),
+		Cursor:      params.Get("cursor"),
+	}
+
+	switch status := domain.AccountStatus(params.Get("status")); status {
+	case "", domain.StatusActive, domain.StatusFrozen, domain.StatusClosed:
+		query.Status = status
+	default:
+		return ports.AccountQuery{}, invalidField("status", "must be 'active', 'frozen' or 'closed'")
+	}
+
+	var err error
+	if query.MinBalance, err = parseMoneyParam(params, "min_balance"); err != nil {
+		return ports.AccountQuery{}, err
+	}
+	if query.MaxBalance, err = parseMoneyParam(params, "max_balance"); err != nil {
+		return ports.AccountQuery{}, err
+	}
+	if query.Limit, err = parseLimit(params); err != nil {
+		return ports.AccountQuery{}, err
+	}
+
+	return query, nil
+}
+
+func (h *httpHandler) FreezeAccountHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeAccountStatus(w, r, h.service.FreezeAccount)
+}
+
+func (h *httpHandler) UnfreezeAccountHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeAccountStatus(w, r, h.service.UnfreezeAccount)
+}
+
+func (h *httpHandler) CloseAccountHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeAccountStatus(w, r, h.service.CloseAccount)
+}
+
+// changeAccountStatus applies a lifecycle change to the account in the path
+// and responds with the updated account. Only operators may change an
+// account's status.
+func (h *httpHandler) changeAccountStatus(w http.ResponseWriter, r *http.Request, change func(context.Context, string) (domain.Account, error)) {
+	accountID := r.PathValue("id")
+
+	if err := requireOperator(r.Context()); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	account, err := change(r.Context(), accountID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(account); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *httpHandler) SetOverdraftLimitHandler(w http.ResponseWriter, r *http.Request) {
+	accountID := r.PathValue("id")
+
+	var req struct {
+		OverdraftLimit domain.Money `json:"overdraft_limit" required:"true"`
+	}
+
+	if err := requireOperator(r.Context()); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	account, err := h.service.SetOverdraftLimit(r.Context(), accountID, req.OverdraftLimit)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(account); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *httpHandler) ListAuditEventsHandler(w http.ResponseWriter, r *http.Request) {
+	accountID := r.PathValue("id")
+
+	if err := h.authorizeAccounts(r.Context(), accountID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	events, err := h.service.ListAuditEvents(r.Context(), accountID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(accounts); err != nil {
+	if err := json.NewEncoder(w).Encode(events); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
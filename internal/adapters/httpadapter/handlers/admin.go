@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+func (h *httpHandler) IssueAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Subject string      `json:"subject" required:"true"`
+		Role    domain.Role `json:"role" required:"true"`
+	}
+
+	if err := requireAdmin(r.Context()); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	token, key, err := h.service.IssueAPIKey(r.Context(), req.Subject, req.Role)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	// The token is only ever disclosed in this response
+	resp := struct {
+		ID        string      `json:"id"`
+		APIKey    string      `json:"api_key"`
+		Subject   string      `json:"subject"`
+		Role      domain.Role `json:"role"`
+		CreatedAt time.Time   `json:"created_at"`
+	}{key.ID, token, key.Principal.Subject, key.Principal.Role, key.CreatedAt}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+func (h *httpHandler) ListTransferApprovalsHandler(w http.ResponseWriter, r *http.Request) {
+	if err := requireOperator(r.Context()); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	status := domain.ApprovalStatus(r.URL.Query().Get("status"))
+	if status != "" && status.Validate() != nil {
+		WriteError(w, r, invalidField("status", "must be 'pending', 'approved' or 'rejected'"))
+		return
+	}
+
+	approvals, err := h.service.ListTransferApprovals(r.Context(), status)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := struct {
+		Approvals []domain.TransferApproval `json:"approvals"`
+	}{approvals}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *httpHandler) GetTransferApprovalHandler(w http.ResponseWriter, r *http.Request) {
+	approvalID := r.PathValue("id")
+
+	principal, err := principalOf(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	approval, err := h.service.GetTransferApproval(r.Context(), approvalID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	// Requesters may follow their own transfers
+	if !principal.IsOperator() && approval.RequestedBy != principal.Subject {
+		WriteError(w, r, domain.ErrForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(approval); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *httpHandler) ApproveTransferHandler(w http.ResponseWriter, r *http.Request) {
+	approvalID := r.PathValue("id")
+
+	principal, err := operatorOf(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	approval, err := h.service.ApproveTransfer(r.Context(), principal.Subject, approvalID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(approval); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (h *httpHandler) RejectTransferHandler(w http.ResponseWriter, r *http.Request) {
+	approvalID := r.PathValue("id")
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+
+	principal, err := operatorOf(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	// The reason is optional, and so is the body that gives it
+	if err := decodeOptionalJSON(w, r, &req); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	approval, err := h.service.RejectTransfer(r.Context(), principal.Subject, approvalID, req.Reason)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(approval); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
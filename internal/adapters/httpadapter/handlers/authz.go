@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// principalOf returns the authenticated caller of the request ctx belongs
+// to.
+func principalOf(ctx context.Context) (domain.Principal, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return domain.Principal{}, domain.ErrUnauthenticated
+	}
+	return principal, nil
+}
+
+// requireOperator allows only operators and admins.
+func requireOperator(ctx context.Context) error {
+	_, err := operatorOf(ctx)
+	return err
+}
+
+// operatorOf returns the authenticated caller if it is an operator or
+// admin.
+func operatorOf(ctx context.Context) (domain.Principal, error) {
+	principal, err := principalOf(ctx)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+	if !principal.IsOperator() {
+		return domain.Principal{}, domain.ErrForbidden
+	}
+	return principal, nil
+}
+
+// requireAdmin allows only admins.
+func requireAdmin(ctx context.Context) error {
+	principal, err := principalOf(ctx)
+	if err != nil {
+		return err
+	}
+	if principal.Role != domain.RoleAdmin {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// authorizeAccounts allows the caller if it may access at least one of
+// the given accounts.
+func (h *httpHandler) authorizeAccounts(ctx context.Context, accountIDs ...string) error {
+	principal, err := principalOf(ctx)
+	if err != nil {
+		return err
+	}
+	if principal.IsOperator() {
+		return nil
+	}
+
+	for _, id := range accountIDs {
+		account, err := h.service.GetAccount(ctx, id)
+		if err != nil {
+			return err
+		}
+		if principal.CanAccess(account) {
+			return nil
+		}
+	}
+	return domain.ErrForbidden
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/problem"
+)
+
+// maxBodyBytes bounds the size of request bodies.
+const maxBodyBytes = 64 << 10
+
+var (
+	errInvalidPayload       = errors.New("invalid request payload")
+	errRequestTooLarge      = errors.New("request body too large")
+	errUnsupportedMediaType = errors.New("content type must be application/json")
+)
+
+// decodeJSON strictly decodes the request body into v, which must point to
+// a struct. The request must be declared as application/json and the body
+// must be a single JSON object of at most maxBodyBytes. Every field must
+// be known to v, and the fields tagged `required:"true"` must be present
+// and not null; violations are reported together in a validationError.
+//
+// A value of the wrong type also fails with a validationError naming its
+// field. Domain errors from the values' own decoding, such as invalid
+// amounts, are returned unchanged.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	if err := checkMediaType(r); err != nil {
+		return err
+	}
+	body, err := readBody(w, r)
+	if err != nil {
+		return err
+	}
+	return decodeBody(body, v)
+}
+
+// decodeOptionalJSON is like decodeJSON, but accepts an empty body, of any
+// content type, as giving none of the fields and leaves v as it is.
+func decodeOptionalJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	body, err := readBody(w, r)
+	if err != nil || len(body) == 0 {
+		return err
+	}
+	if err := checkMediaType(r); err != nil {
+		return err
+	}
+	return decodeBody(body, v)
+}
+
+// checkMediaType returns errUnsupportedMediaType unless the request is
+// declared as application/json.
+func checkMediaType(r *http.Request) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errUnsupportedMediaType
+	}
+	return nil
+}
+
+// readBody reads the request body, of at most maxBodyBytes.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return nil, errRequestTooLarge
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+	return body, nil
+}
+
+// decodeBody decodes a JSON request body into v as decodeJSON describes.
+func decodeBody(body []byte, v any) error {
+	// A first pass finds which fields are present, so that missing and
+	// unknown fields can be reported by name
+	var present map[string]json.RawMessage
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if err := dec.Decode(&present); err != nil || present == nil {
+		return fmt.Errorf("%w: body must be a JSON object", errInvalidPayload)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("%w: unexpected data after the JSON object", errInvalidPayload)
+	}
+	if err := checkFields(reflect.TypeOf(v).Elem(), present); err != nil {
+		return err
+	}
+
+	dec = json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+
+	var typeErr *json.UnmarshalTypeError
+	switch status, _ := describeError(err); {
+	case err == nil:
+		return nil
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return invalidField(typeErr.Field, fmt.Sprintf("must not be a JSON %s", typeErr.Value))
+	case status != http.StatusInternalServerError:
+		return err
+	default:
+		return fmt.Errorf("%w: %v", errInvalidPayload, err)
+	}
+}
+
+// checkFields compares the fields present in a JSON object with those of
+// the struct type t, returning a validationError listing the required
+// fields that are missing or null, then the fields t does not have.
+func checkFields(t reflect.Type, present map[string]json.RawMessage) error {
+	var fields []problem.FieldError
+	known := make(map[string]bool, t.NumField())
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[name] = true
+
+		raw, ok := present[name]
+		if field.Tag.Get("required") == "true" && (!ok || string(raw) == "null") {
+			fields = append(fields, problem.FieldError{Field: name, Message: "is required"})
+		}
+	}
+
+	var unknown []string
+	for name := range present {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	slices.Sort(unknown)
+	for _, name := range unknown {
+		fields = append(fields, problem.FieldError{Field: name, Message: "is not a known field"})
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	return &validationError{fields: fields}
+}
@@ -1,29 +1,127 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strings"
 
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/problem"
 	"github.com/hesampakdaman/banking-service/internal/domain"
 )
 
-func domainErrToStatusCode(err error) int {
-	switch {
-	case errors.Is(err, domain.ErrInvalidOwner),
-		errors.Is(err, domain.ErrInvalidTransactionType),
-		errors.Is(err, domain.ErrNegativeBalance),
-		errors.Is(err, domain.ErrSelfTransfer),
-		errors.Is(err, domain.ErrInvalidAmount):
-		return http.StatusBadRequest
+// errorMapping is the response to an error: its status and the stable
+// problem code clients can rely on.
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings is consulted in order; the first entry whose error matches
+// (with errors.Is) decides the response.
+var errorMappings = []errorMapping{
+	{errInvalidPayload, http.StatusBadRequest, "invalid_payload"},
+	{errRequestTooLarge, http.StatusRequestEntityTooLarge, "request_too_large"},
+	{errUnsupportedMediaType, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	{domain.ErrInvalidOwner, http.StatusBadRequest, "invalid_owner"},
+	{domain.ErrInvalidTransactionType, http.StatusBadRequest, "invalid_transaction_type"},
+	{domain.ErrNegativeBalance, http.StatusBadRequest, "negative_balance"},
+	{domain.ErrSelfTransfer, http.StatusBadRequest, "self_transfer"},
+	{domain.ErrInvalidAmount, http.StatusBadRequest, "invalid_amount"},
+	{domain.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{domain.ErrInvalidOverdraftLimit, http.StatusBadRequest, "invalid_overdraft_limit"},
+	{domain.ErrInvalidMoney, http.StatusBadRequest, "invalid_money"},
+	{domain.ErrAmountPrecision, http.StatusBadRequest, "amount_precision"},
+	{domain.ErrAmountOverflow, http.StatusBadRequest, "amount_overflow"},
+	{domain.ErrInvalidCurrency, http.StatusBadRequest, "invalid_currency"},
+	{domain.ErrCurrencyMismatch, http.StatusBadRequest, "currency_mismatch"},
+	{domain.ErrInvalidSubject, http.StatusBadRequest, "invalid_subject"},
+	{domain.ErrInvalidRole, http.StatusBadRequest, "invalid_role"},
+	{domain.ErrInvalidApprovalStatus, http.StatusBadRequest, "invalid_approval_status"},
+
+	{domain.ErrUnauthenticated, http.StatusUnauthorized, "unauthenticated"},
+	{domain.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{domain.ErrSelfApproval, http.StatusForbidden, "self_approval"},
+
+	{domain.ErrAccountAlreadyExists, http.StatusConflict, "account_already_exists"},
+	{domain.ErrInsufficientFunds, http.StatusConflict, "insufficient_funds"},
+	{domain.ErrAccountNotEmpty, http.StatusConflict, "account_not_empty"},
+	{domain.ErrOverdraftInUse, http.StatusConflict, "overdraft_in_use"},
+	{domain.ErrInvalidStatusTransition, http.StatusConflict, "invalid_status_transition"},
+	{domain.ErrVersionConflict, http.StatusConflict, "version_conflict"},
+	{domain.ErrAPIKeyAlreadyExists, http.StatusConflict, "api_key_already_exists"},
+	{domain.ErrApprovalAlreadyExists, http.StatusConflict, "approval_already_exists"},
+	{domain.ErrApprovalNotPending, http.StatusConflict, "approval_not_pending"},
 
-	case errors.Is(err, domain.ErrAccountAlreadyExists),
-		errors.Is(err, domain.ErrInsufficientFunds):
-		return http.StatusConflict
+	{domain.ErrAccountFrozen, http.StatusLocked, "account_frozen"},
+	{domain.ErrAccountClosed, http.StatusGone, "account_closed"},
 
-	case errors.Is(err, domain.ErrInvalidAccountID):
-		return http.StatusNotFound
+	{domain.ErrInvalidAccountID, http.StatusNotFound, "account_not_found"},
+	{domain.ErrTransferNotFound, http.StatusNotFound, "transfer_not_found"},
+	{domain.ErrApprovalNotFound, http.StatusNotFound, "approval_not_found"},
+
+	{domain.ErrExchangeRateUnavailable, http.StatusUnprocessableEntity, "exchange_rate_unavailable"},
+
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout"},
+	{context.Canceled, problem.StatusClientClosedRequest, "request_cancelled"},
+}
 
-	default:
-		return http.StatusInternalServerError
+// validationError rejects a request because of one or more of its fields
+// or query parameters.
+type validationError struct {
+	fields []problem.FieldError
+}
+
+// invalidField returns a validationError for a single field.
+func invalidField(field, message string) *validationError {
+	return &validationError{fields: []problem.FieldError{{Field: field, Message: message}}}
+}
+
+func (e *validationError) Error() string {
+	msgs := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		msgs[i] = f.Field + ": " + f.Message
 	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+// describeError returns the status and problem code for err. Unknown
+// errors are internal errors.
+func describeError(err error) (int, string) {
+	var validationErr *validationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, "validation_failed"
+	}
+
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.status, m.code
+		}
+	}
+	return http.StatusInternalServerError, "internal_error"
+}
+
+// WriteError responds to r with the problem describing err. The message of
+// an internal error is not disclosed.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code := describeError(err)
+
+	var fields []problem.FieldError
+	var validationErr *validationError
+	if errors.As(err, &validationErr) {
+		fields = validationErr.fields
+	}
+
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", auth.Challenge)
+	}
+
+	detail := err.Error()
+	if status == http.StatusInternalServerError {
+		detail = "An unexpected error occurred"
+	}
+
+	problem.Write(w, r, status, code, detail, fields...)
 }
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func (h *httpHandler) CheckLedgerHandler(w http.ResponseWriter, r *http.Request) {
+	if err := requireOperator(r.Context()); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	check, err := h.service.CheckLedger(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(check); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// parseTimeParam parses the named query parameter as an RFC 3339
+// timestamp, returning the zero time if it is absent.
+func parseTimeParam(params url.Values, name string) (time.Time, error) {
+	v := params.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, invalidField(name, "must be an RFC 3339 timestamp")
+	}
+	return t, nil
+}
+
+// parseMoneyParam parses the named query parameter as an amount, returning
+// nil if it is absent.
+func parseMoneyParam(params url.Values, name string) (*domain.Money, error) {
+	v := params.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	amount, err := domain.ParseMoney(v)
+	if err != nil {
+		return nil, invalidField(name, err.Error())
+	}
+	return &amount, nil
+}
+
+// parseLimit parses the limit query parameter, returning 0 (the default
+// page size) if it is absent.
+func parseLimit(params url.Values) (int, error) {
+	v := params.Get("limit")
+	if v == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 {
+		return 0, invalidField("limit", "must be a positive integer")
+	}
+	return limit, nil
+}
@@ -3,20 +3,27 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 
 	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 )
 
 func (h *httpHandler) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
 	accountID := r.PathValue("id")
 
 	var req struct {
-		Type   string  `json:"type"` // "deposit" or "withdrawal"
-		Amount float64 `json:"amount"`
+		Type     string          `json:"type" required:"true"` // "deposit" or "withdrawal"
+		Amount   domain.Money    `json:"amount" required:"true"`
+		Currency domain.Currency `json:"currency" required:"true"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+	if err := h.authorizeAccounts(r.Context(), accountID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	if err := decodeJSON(w, r, &req); err != nil {
+		WriteError(w, r, err)
 		return
 	}
 
@@ -27,13 +34,22 @@ func (h *httpHandler) CreateTransactionHandler(w http.ResponseWriter, r *http.Re
 	case "withdrawal":
 		txnType = domain.Withdrawal
 	default:
-		http.Error(w, "Invalid transaction type (must be 'deposit' or 'withdrawal')", http.StatusBadRequest)
+		WriteError(w, r, invalidField("type", "must be 'deposit' or 'withdrawal'"))
 		return
 	}
 
-	transaction, err := h.service.CreateTransaction(r.Context(), accountID, txnType, req.Amount)
+	// Deposits are booked against cash the bank has received, which only
+	// operators can vouch for
+	if txnType == domain.Deposit {
+		if err := requireOperator(r.Context()); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+	}
+
+	transaction, err := h.service.CreateTransaction(r.Context(), accountID, txnType, req.Amount, req.Currency)
 	if err != nil {
-		http.Error(w, err.Error(), domainErrToStatusCode(err))
+		WriteError(w, r, err)
 		return
 	}
 
@@ -46,40 +62,140 @@ func (h *httpHandler) CreateTransactionHandler(w http.ResponseWriter, r *http.Re
 
 func (h *httpHandler) TransferHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		FromAccountID string  `json:"from_account_id"`
-		ToAccountID   string  `json:"to_account_id"`
-		Amount        float64 `json:"amount"`
+		FromAccountID string          `json:"from_account_id" required:"true"`
+		ToAccountID   string          `json:"to_account_id" required:"true"`
+		Amount        domain.Money    `json:"amount" required:"true"`
+		Currency      domain.Currency `json:"currency" required:"true"`
+	}
+
+	if err := decodeJSON(w, r, &req); err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	// Money may be sent to any account, but only taken from one's own
+	if err := h.authorizeAccounts(r.Context(), req.FromAccountID); err != nil {
+		WriteError(w, r, err)
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+	principal, err := principalOf(r.Context())
+	if err != nil {
+		WriteError(w, r, err)
 		return
 	}
 
-	fromTxn, toTxn, err := h.service.Transfer(r.Context(), req.FromAccountID, req.ToAccountID, req.Amount)
+	transfer, approval, err := h.service.RequestTransfer(r.Context(), principal.Subject, req.FromAccountID, req.ToAccountID, req.Amount, req.Currency)
 	if err != nil {
-		http.Error(w, err.Error(), domainErrToStatusCode(err))
+		WriteError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+
+	// A transfer held for approval has not been made yet
+	if approval.ID != "" {
+		w.WriteHeader(http.StatusAccepted)
+		if err := json.NewEncoder(w).Encode(approval); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+
 	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(map[string]string{
-		"withdrawal_transaction_id": fromTxn.ID,
-		"deposit_transaction_id":    toTxn.ID,
+		"transfer_id":               transfer.ID,
+		"withdrawal_transaction_id": transfer.Withdrawal.ID,
+		"deposit_transaction_id":    transfer.Deposit.ID,
 	}); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
 func (h *httpHandler) ListTransactionsHandler(w http.ResponseWriter, r *http.Request) {
-	accountID := r.PathValue("id")
+	query, err := parseTransactionQuery(r.URL.Query())
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	query.AccountID = r.PathValue("id")
+
+	if err := h.authorizeAccounts(r.Context(), query.AccountID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
 
-	transactions := h.service.ListTransactions(r.Context(), accountID)
+	page, err := h.service.QueryTransactions(r.Context(), query)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+
+	resp := struct {
+		Transactions []domain.Transaction `json:"transactions"`
+		NextCursor   string               `json:"next_cursor,omitempty"`
+	}{page.Transactions, page.NextCursor}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// parseTransactionQuery reads the filters and paging parameters of
+// ListTransactionsHandler: from and to (RFC 3339), type, min_amount,
+// max_amount, cursor and limit. Absent parameters are left unset.
+func parseTransactionQuery(params url.Values) (ports.TransactionQuery, error) {
+	query := ports.TransactionQuery{Cursor: params.Get("cursor")}
+
+	var err error
+	if query.From, err = parseTimeParam(params, "from"); err != nil {
+		return ports.TransactionQuery{}, err
+	}
+	if query.To, err = parseTimeParam(params, "to"); err != nil {
+		return ports.TransactionQuery{}, err
+	}
+
+	switch v := params.Get("type"); v {
+	case "":
+	case "deposit":
+		query.Type = domain.Deposit
+	case "withdrawal":
+		query.Type = domain.Withdrawal
+	default:
+		return ports.TransactionQuery{}, invalidField("type", "must be 'deposit' or 'withdrawal'")
+	}
+
+	if query.MinAmount, err = parseMoneyParam(params, "min_amount"); err != nil {
+		return ports.TransactionQuery{}, err
+	}
+	if query.MaxAmount, err = parseMoneyParam(params, "max_amount"); err != nil {
+		return ports.TransactionQuery{}, err
+	}
+	if query.Limit, err = parseLimit(params); err != nil {
+		return ports.TransactionQuery{}, err
+	}
+
+	return query, nil
+}
+
+func (h *httpHandler) GetTransferHandler(w http.ResponseWriter, r *http.Request) {
+	transferID := r.PathValue("id")
+
+	transfer, err := h.service.GetTransfer(r.Context(), transferID)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	// Either party of the transfer may see it
+	if err := h.authorizeAccounts(r.Context(), transfer.Withdrawal.AccountID, transfer.Deposit.AccountID); err != nil {
+		WriteError(w, r, err)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(transactions); err != nil {
+	if err := json.NewEncoder(w).Encode(transfer); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
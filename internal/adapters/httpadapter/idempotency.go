@@ -0,0 +1,224 @@
+package httpadapter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/problem"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/requestid"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header clients use to make a POST
+	// request safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on responses replayed from the store.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodyBytes  = 1 << 20
+)
+
+// IdempotencyStore remembers the first response to each idempotency key
+// for a fixed window, so that retried requests can be answered with it.
+type IdempotencyStore struct {
+	mu        sync.Mutex
+	window    time.Duration
+	now       func() time.Time
+	nextSweep time.Time
+	entries   map[string]*idempotencyEntry
+}
+
+// idempotencyEntry is a request that is either still being handled
+// (done == false) or whose response has been captured.
+type idempotencyEntry struct {
+	fingerprint [sha256.Size]byte
+	done        bool
+	status      int
+	header      http.Header
+	body        []byte
+	expires     time.Time
+}
+
+// NewIdempotencyStore returns a store that keeps responses for window.
+func NewIdempotencyStore(window time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		window:  window,
+		now:     time.Now,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+// IdempotencyMiddleware honors the Idempotency-Key header on POST requests.
+// The first response for a key is stored and replayed for later requests
+// with the same key and payload; reusing a key with a different payload is
+// rejected with 422, and a retry arriving while the original is still being
+// handled is rejected with 409. Server errors, rate limiting and version
+// conflicts are not stored, so they can be retried. A legacy unversioned
+// path is the same request as its versioned successor. Keys are scoped to
+// the authenticated principal, so one caller can never be served another's
+// response.
+func IdempotencyMiddleware(next http.Handler, store *IdempotencyStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			problem.Write(w, r, http.StatusBadRequest, "idempotency_key_too_long", "Idempotency-Key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodyBytes))
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			problem.Write(w, r, http.StatusRequestEntityTooLarge, "request_too_large", "Request body too large")
+			return
+		case err != nil:
+			problem.Write(w, r, http.StatusBadRequest, "invalid_payload", "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := sha256.Sum256([]byte(r.Method + " " + canonicalPath(r.URL.Path) + "\n" + string(body)))
+		if principal, ok := auth.FromContext(r.Context()); ok {
+			key = principal.Subject + "\x00" + key
+		}
+
+		entry, isNew := store.begin(key, fingerprint)
+		switch {
+		case entry.fingerprint != fingerprint:
+			problem.Write(w, r, http.StatusUnprocessableEntity, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+			return
+		case !isNew && !entry.done:
+			problem.Write(w, r, http.StatusConflict, "idempotent_request_in_progress", "A request with this Idempotency-Key is still being processed")
+			return
+		case !isNew:
+			replay(w, entry)
+			return
+		}
+
+		rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			// Also release the key if the handler panicked
+			store.finish(key, rec)
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// begin returns a copy of the entry for key, creating an in-flight entry if
+// there is none (or only an expired one).
+func (s *IdempotencyStore) begin(key string, fingerprint [sha256.Size]byte) (idempotencyEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if entry, exists := s.entries[key]; exists && (!entry.done || now.Before(entry.expires)) {
+		return *entry, false
+	}
+
+	entry := &idempotencyEntry{fingerprint: fingerprint}
+	s.entries[key] = entry
+	return *entry, true
+}
+
+// finish stores the recorded response for key, or forgets the key if the
+// response should not be replayed.
+func (s *IdempotencyStore) finish(key string, rec *recordingWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !rec.wroteHeader || retryable(rec) {
+		delete(s.entries, key)
+		return
+	}
+
+	entry := s.entries[key]
+	entry.done = true
+	entry.status = rec.status
+	entry.header = rec.header
+	entry.body = rec.body.Bytes()
+	entry.expires = s.now().Add(s.window)
+}
+
+// retryable reports whether a recorded response may be different if the
+// request is sent again: a server error, rate limiting or a lost race on
+// an account's version.
+func retryable(rec *recordingWriter) bool {
+	switch {
+	case rec.status >= http.StatusInternalServerError, rec.status == http.StatusTooManyRequests:
+		return true
+	case rec.status == http.StatusConflict:
+		var details problem.Details
+		return json.Unmarshal(rec.body.Bytes(), &details) == nil && details.Code == "version_conflict"
+	default:
+		return false
+	}
+}
+
+// sweep drops expired entries, at most once per window.
+func (s *IdempotencyStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+
+	for key, entry := range s.entries {
+		if entry.done && !now.Before(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+	s.nextSweep = now.Add(s.window)
+}
+
+// replay writes a stored response. The request ID header keeps identifying
+// the retry rather than the original request.
+func replay(w http.ResponseWriter, entry idempotencyEntry) {
+	for name, values := range entry.header {
+		if name != requestid.Header {
+			w.Header()[name] = values
+		}
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(entry.status)
+	_, _ = w.Write(entry.body)
+}
+
+// recordingWriter passes a response through while keeping a copy of it.
+type recordingWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.status = code
+		rw.header = rw.ResponseWriter.Header().Clone()
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
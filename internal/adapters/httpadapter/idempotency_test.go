@@ -0,0 +1,154 @@
+package httpadapter
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/problem"
+	"gotest.tools/assert"
+)
+
+func TestIdempotencyMiddleware_KeysExpire(t *testing.T) {
+	// Given: A store with a one-hour window and a controllable clock
+	now := time.Date(2025, 2, 12, 12, 0, 0, 0, time.UTC)
+	store := NewIdempotencyStore(time.Hour)
+	store.now = func() time.Time { return now }
+
+	calls := 0
+	handler := IdempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "call %d", calls)
+	}), store)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// When: The request is retried within the window
+	first := send()
+	now = now.Add(59 * time.Minute)
+	second := send()
+
+	// Then: The first response is replayed
+	assert.Equal(t, first.Body.String(), "call 1")
+	assert.Equal(t, second.Body.String(), "call 1")
+	assert.Equal(t, second.Code, http.StatusCreated)
+
+	// And: After the window the key is forgotten and the request runs again
+	now = now.Add(2 * time.Minute)
+	third := send()
+	assert.Equal(t, third.Body.String(), "call 2")
+	assert.Equal(t, calls, 2)
+}
+
+func TestIdempotencyMiddleware_ServerErrorsAreNotStored(t *testing.T) {
+	// Given: A handler that fails on its first call
+	calls := 0
+	handler := IdempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}), NewIdempotencyStore(time.Hour))
+
+	send := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/transfer", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// When: The request is retried after the failure
+	// Then: The retry is executed rather than replaying the error
+	assert.Equal(t, send(), http.StatusInternalServerError)
+	assert.Equal(t, send(), http.StatusCreated)
+	assert.Equal(t, calls, 2)
+}
+
+func TestIdempotencyMiddleware_RetryableResponsesAreNotStored(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		code       string
+		wantStored bool
+	}{
+		{name: "Rate limited", status: http.StatusTooManyRequests, code: "rate_limited"},
+		{name: "Version conflict", status: http.StatusConflict, code: "version_conflict"},
+		{name: "Other conflict", status: http.StatusConflict, code: "approval_not_pending", wantStored: true},
+		{name: "Client error", status: http.StatusUnprocessableEntity, code: "insufficient_funds", wantStored: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A handler that fails on its first call
+			calls := 0
+			handler := IdempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					problem.Write(w, r, tc.status, tc.code, "")
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+			}), NewIdempotencyStore(time.Hour))
+
+			send := func() int {
+				req := httptest.NewRequest(http.MethodPost, "/v1/transfer", strings.NewReader(`{}`))
+				req.Header.Set(IdempotencyKeyHeader, "key-1")
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec.Code
+			}
+
+			// When: The request is retried after the failure
+			first, second := send(), send()
+
+			// Then: Only responses that would be the same again are replayed
+			assert.Equal(t, first, tc.status)
+			if tc.wantStored {
+				assert.Equal(t, second, tc.status)
+				assert.Equal(t, calls, 1)
+			} else {
+				assert.Equal(t, second, http.StatusCreated)
+				assert.Equal(t, calls, 2)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_UnversionedAliasIsSameRequest(t *testing.T) {
+	// Given: A request made at the versioned path
+	calls := 0
+	handler := IdempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}), NewIdempotencyStore(time.Hour))
+
+	send := func(path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, send("/v1/transfer"), http.StatusCreated)
+
+	// When: It is retried at its unversioned alias
+	// Then: The response is replayed rather than the key reported as reused
+	assert.Equal(t, send("/transfer"), http.StatusCreated)
+	assert.Equal(t, calls, 1)
+
+	// And: The key still cannot be reused for another route
+	assert.Equal(t, send("/accounts"), http.StatusUnprocessableEntity)
+}
@@ -2,6 +2,7 @@ package integrationtest
 
 import (
 	"net/http"
+	"slices"
 	"testing"
 
 	"github.com/hesampakdaman/banking-service/internal/domain"
@@ -12,7 +13,7 @@ func TestGetAccount_NotFound(t *testing.T) {
 	server := setupTestServer(t)
 
 	// When: Trying to retrieve a non-existent account
-	resp := getJSON(t, server.URL+"/accounts/non-existent-id")
+	resp := getJSON(t, server.URL+"/v1/accounts/non-existent-id")
 
 	// Then: The response should indicate not found
 	assert.Equal(t, resp.StatusCode, http.StatusNotFound)
@@ -22,9 +23,10 @@ func TestCreateAndGetAccount(t *testing.T) {
 	server := setupTestServer(t)
 
 	// Given: A new account request with valid data
-	resp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
 
 	// When: The account is created
@@ -35,13 +37,13 @@ func TestCreateAndGetAccount(t *testing.T) {
 	assert.Assert(t, exists, "account_id should exist in response")
 
 	// And: We retrieve the created account
-	resp = getJSON(t, server.URL+"/accounts/"+accountID)
+	resp = getJSON(t, server.URL+"/v1/accounts/"+accountID)
 
 	// Then: The response should contain the correct actual details
 	var actual domain.Account
 	parseJSON(t, resp, &actual)
 
-	expected, _ := domain.NewAccount(accountID, "Alice", 1000.0)
+	expected, _ := domain.NewAccount(accountID, "Alice", "USD", domain.MustParseMoney("1000"))
 	assert.DeepEqual(t, expected, actual)
 }
 
@@ -56,7 +58,8 @@ func TestCreateAccount_InvalidInput(t *testing.T) {
 		{
 			name: "Missing owner",
 			request: map[string]interface{}{
-				"initial_balance": 1000,
+				"initial_balance": "1000",
+				"currency":        "USD",
 			},
 			wantStatus: http.StatusBadRequest,
 		},
@@ -64,7 +67,25 @@ func TestCreateAccount_InvalidInput(t *testing.T) {
 			name: "Negative balance",
 			request: map[string]interface{}{
 				"owner":           "Alice",
-				"initial_balance": -500,
+				"initial_balance": "-500",
+				"currency":        "USD",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Missing currency",
+			request: map[string]interface{}{
+				"owner":           "Alice",
+				"initial_balance": "1000",
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Unsupported currency",
+			request: map[string]interface{}{
+				"owner":           "Alice",
+				"initial_balance": "1000",
+				"currency":        "XYZ",
 			},
 			wantStatus: http.StatusBadRequest,
 		},
@@ -77,7 +98,7 @@ func TestCreateAccount_InvalidInput(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			resp := postJSON(t, server.URL+"/accounts", tc.request)
+			resp := postJSON(t, server.URL+"/v1/accounts", tc.request)
 			assert.Equal(t, resp.StatusCode, tc.wantStatus)
 		})
 	}
@@ -92,16 +113,18 @@ func TestCreateAccount_Duplicate(t *testing.T) {
 	domain.GetUUID = func() string { return "fixed-uuid" }
 
 	// Given: A valid account request
-	resp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
 	assert.Equal(t, resp.StatusCode, http.StatusCreated)
 
 	// When: Trying to create an account with the same UUID
-	resp = postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	resp = postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Bob",
-		"initial_balance": 500,
+		"initial_balance": "500",
+		"currency":        "USD",
 	})
 
 	// Then: The response should indicate conflict
@@ -112,18 +135,106 @@ func TestListAccounts(t *testing.T) {
 	server := setupTestServer(t)
 
 	// Given: A single account exists
-	postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
 
 	// When: We retrieve the list of accounts
-	resp := getJSON(t, server.URL+"/accounts")
+	resp := getJSON(t, server.URL+"/v1/accounts")
 
 	// Then: The response should contain exactly one account
-	var actual []domain.Account
-	parseJSON(t, resp, &actual)
+	var page accountPage
+	parseJSON(t, resp, &page)
+	actual := page.Accounts
 
-	expected, _ := domain.NewAccount(actual[0].ID, "Alice", 1000.0)
+	expected, _ := domain.NewAccount(actual[0].ID, "Alice", "USD", domain.MustParseMoney("1000"))
 	assert.DeepEqual(t, []domain.Account{expected}, actual)
+	assert.Equal(t, page.Total, 1)
+	assert.Equal(t, page.NextCursor, "")
+}
+
+func TestListAccounts_PaginatedAndFiltered(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: Accounts owned by Alice, Alan and Bob, one of Alan's frozen
+	balances := map[string][]string{
+		"Alice": {"100", "500"},
+		"alan":  {"300", "700"},
+		"Bob":   {"400"},
+	}
+	var frozenID string
+	for owner, amounts := range balances {
+		for _, amount := range amounts {
+			resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+				"owner":           owner,
+				"initial_balance": amount,
+				"currency":        "USD",
+			})
+			var createResp map[string]string
+			parseJSON(t, resp, &createResp)
+			if owner == "alan" && amount == "700" {
+				frozenID = createResp["account_id"]
+			}
+		}
+	}
+	resp := postJSON(t, server.URL+"/v1/accounts/"+frozenID+"/freeze", nil)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	resp.Body.Close()
+
+	// When: Paging through active accounts of owners starting with "AL" and
+	// a balance of at least 200, one at a time
+	var owners []string
+	var totals []int
+	url := server.URL + "/v1/accounts?owner=AL&status=active&min_balance=200&limit=1"
+	next := url
+	for next != "" {
+		resp = getJSON(t, next)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		var page accountPage
+		parseJSON(t, resp, &page)
+		for _, account := range page.Accounts {
+			owners = append(owners, account.Owner+":"+account.Balance.String())
+		}
+		totals = append(totals, page.Total)
+
+		next = ""
+		if page.NextCursor != "" {
+			next = url + "&cursor=" + page.NextCursor
+		}
+	}
+
+	// Then: Exactly the matching accounts should be returned, each once
+	slices.Sort(owners)
+	assert.DeepEqual(t, owners, []string{"Alice:500.00", "alan:300.00"})
+
+	// And: Every page should report the total number of matches
+	assert.DeepEqual(t, totals, []int{2, 2})
+}
+
+func TestListAccounts_InvalidQuery(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "Invalid status", query: "status=dormant"},
+		{name: "Invalid balance", query: "min_balance=lots"},
+		{name: "Invalid limit", query: "limit=zero"},
+		{name: "Invalid cursor", query: "cursor=%25%25"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Listing accounts with a malformed query parameter
+			resp := getJSON(t, server.URL+"/v1/accounts?"+tc.query)
+			defer resp.Body.Close()
+
+			// Then: It should be rejected
+			assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+		})
+	}
 }
@@ -0,0 +1,303 @@
+package integrationtest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+// openAccount opens an account for owner holding balance USD, as the test
+// operator.
+func openAccount(t *testing.T, serverURL, owner, balance string) string {
+	t.Helper()
+
+	resp := postJSON(t, serverURL+"/v1/accounts", map[string]interface{}{
+		"owner":           owner,
+		"initial_balance": balance,
+		"currency":        "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+	var created map[string]string
+	parseJSON(t, resp, &created)
+	return created["account_id"]
+}
+
+// requestTransfer asks for a transfer above testApprovalThreshold and
+// returns the pending approval.
+func requestTransfer(t *testing.T, serverURL string, headers map[string]string, fromID, toID, amount string) domain.TransferApproval {
+	t.Helper()
+
+	resp := postJSONWithHeaders(t, serverURL+"/v1/transfer", headers, map[string]interface{}{
+		"from_account_id": fromID,
+		"to_account_id":   toID,
+		"amount":          amount,
+		"currency":        "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusAccepted)
+
+	var approval domain.TransferApproval
+	parseJSON(t, resp, &approval)
+	assert.Equal(t, approval.Status, domain.ApprovalPending)
+	return approval
+}
+
+// balanceOf returns the balance of an account, read as the test operator.
+func balanceOf(t *testing.T, serverURL, accountID string) string {
+	t.Helper()
+
+	resp := getJSON(t, serverURL+"/v1/accounts/"+accountID)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	var account domain.Account
+	parseJSON(t, resp, &account)
+	return account.Balance.String()
+}
+
+func TestApproval_TransferAtThresholdIsImmediate(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: Two accounts
+	fromID := openAccount(t, server.URL, "Alice", "5000")
+	toID := openAccount(t, server.URL, "Bob", "0")
+
+	// When: Transferring exactly the approval threshold
+	resp := postJSON(t, server.URL+"/v1/transfer", map[string]interface{}{
+		"from_account_id": fromID,
+		"to_account_id":   toID,
+		"amount":          testApprovalThreshold.Amount.String(),
+		"currency":        "USD",
+	})
+	defer resp.Body.Close()
+
+	// Then: The transfer should be made at once
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+	assert.Equal(t, balanceOf(t, server.URL, toID), "1000.00")
+}
+
+func TestApproval_SecondOperatorApproves(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: A transfer above the threshold requested by the test operator
+	fromID := openAccount(t, server.URL, "Alice", "5000")
+	toID := openAccount(t, server.URL, "Bob", "0")
+	approval := requestTransfer(t, server.URL, nil, fromID, toID, "2500")
+	approvalURL := server.URL + "/v1/transfer-approvals/" + approval.ID
+
+	assert.Equal(t, approval.RequestedBy, "test-operator")
+	assert.Equal(t, balanceOf(t, server.URL, fromID), "5000.00")
+
+	// When: The requester approves it
+	resp := postJSON(t, approvalURL+"/approve", map[string]interface{}{})
+
+	// Then: The approval should be refused
+	assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+	assert.Equal(t, parseProblem(t, resp).Code, "self_approval")
+
+	// When: A second operator approves it
+	olivia := map[string]string{auth.APIKeyHeader: issueKey(t, server.URL, "Olivia", "operator")}
+	resp = postJSONWithHeaders(t, approvalURL+"/approve", olivia, map[string]interface{}{})
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	var approved domain.TransferApproval
+	parseJSON(t, resp, &approved)
+
+	// Then: The transfer should be made and the decision recorded
+	assert.Equal(t, approved.Status, domain.ApprovalApproved)
+	assert.Assert(t, approved.TransferID != "", "transfer_id should be set")
+	assert.Equal(t, len(approved.History), 2)
+	assert.Equal(t, approved.History[1].Actor, "Olivia")
+	assert.Equal(t, balanceOf(t, server.URL, fromID), "2500.00")
+	assert.Equal(t, balanceOf(t, server.URL, toID), "2500.00")
+
+	resp = getJSON(t, server.URL+"/v1/transfers/"+approved.TransferID)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	resp.Body.Close()
+
+	// And: It should not be decided on again
+	resp = postJSONWithHeaders(t, approvalURL+"/reject", olivia, map[string]interface{}{})
+	assert.Equal(t, resp.StatusCode, http.StatusConflict)
+	assert.Equal(t, parseProblem(t, resp).Code, "approval_not_pending")
+
+	resp = getJSON(t, approvalURL)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	var stored domain.TransferApproval
+	parseJSON(t, resp, &stored)
+	assert.Equal(t, stored.Status, domain.ApprovalApproved)
+	assert.Equal(t, stored.TransferID, approved.TransferID)
+}
+
+func TestApproval_Reject(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: Two pending transfers
+	fromID := openAccount(t, server.URL, "Alice", "5000")
+	toID := openAccount(t, server.URL, "Bob", "0")
+	rejected := requestTransfer(t, server.URL, nil, fromID, toID, "2500")
+	pending := requestTransfer(t, server.URL, nil, fromID, toID, "1500")
+
+	// When: A second operator rejects the first
+	olivia := map[string]string{auth.APIKeyHeader: issueKey(t, server.URL, "Olivia", "operator")}
+	resp := postJSONWithHeaders(t, server.URL+"/v1/transfer-approvals/"+rejected.ID+"/reject", olivia, map[string]interface{}{
+		"reason": "Unexpected beneficiary",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	var approval domain.TransferApproval
+	parseJSON(t, resp, &approval)
+
+	// Then: It should be rejected, with the reason, and no money moved
+	assert.Equal(t, approval.Status, domain.ApprovalRejected)
+	assert.Equal(t, approval.History[1].Reason, "Unexpected beneficiary")
+	assert.Equal(t, balanceOf(t, server.URL, fromID), "5000.00")
+
+	// And: Only the other transfer should still be pending
+	resp = getJSON(t, server.URL+"/v1/transfer-approvals?status=pending")
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	var list struct {
+		Approvals []domain.TransferApproval `json:"approvals"`
+	}
+	parseJSON(t, resp, &list)
+	assert.Equal(t, len(list.Approvals), 1)
+	assert.Equal(t, list.Approvals[0].ID, pending.ID)
+
+	resp = getJSON(t, server.URL+"/v1/transfer-approvals")
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	parseJSON(t, resp, &list)
+	assert.Equal(t, len(list.Approvals), 2)
+}
+
+func TestApproval_RejectWithoutBody(t *testing.T) {
+	server := setupTestServer(t)
+	olivia := issueKey(t, server.URL, "Olivia", "operator")
+
+	fromID := openAccount(t, server.URL, "Alice", "5000")
+	toID := openAccount(t, server.URL, "Bob", "0")
+
+	for _, contentType := range []string{"", "application/json"} {
+		// Given: A pending transfer
+		approval := requestTransfer(t, server.URL, nil, fromID, toID, "2500")
+
+		// When: A second operator rejects it without a body
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/v1/transfer-approvals/"+approval.ID+"/reject", nil)
+		assert.NilError(t, err)
+		req.Header.Set(auth.APIKeyHeader, olivia)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		resp := send(t, req)
+
+		// Then: It should be rejected without a reason
+		assert.Equal(t, resp.StatusCode, http.StatusOK, "Content-Type %q", contentType)
+		parseJSON(t, resp, &approval)
+		assert.Equal(t, approval.Status, domain.ApprovalRejected)
+		assert.Equal(t, approval.History[1].Reason, "")
+	}
+}
+
+func TestApproval_CustomerRequests(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: A customer Alice and her transfer above the threshold
+	alice := map[string]string{auth.APIKeyHeader: issueKey(t, server.URL, "Alice", "customer")}
+	fromID := openAccount(t, server.URL, "Alice", "5000")
+	toID := openAccount(t, server.URL, "Bob", "0")
+	approval := requestTransfer(t, server.URL, alice, fromID, toID, "2500")
+	approvalURL := server.URL + "/v1/transfer-approvals/" + approval.ID
+
+	t.Run("Follows her own request", func(t *testing.T) {
+		resp := getJSONWithHeaders(t, approvalURL, alice)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		resp.Body.Close()
+	})
+
+	t.Run("Does not see other requests", func(t *testing.T) {
+		bob := map[string]string{auth.APIKeyHeader: issueKey(t, server.URL, "Bob", "customer")}
+		resp := getJSONWithHeaders(t, approvalURL, bob)
+		assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+		resp.Body.Close()
+	})
+
+	t.Run("Cannot list or decide", func(t *testing.T) {
+		resp := getJSONWithHeaders(t, server.URL+"/v1/transfer-approvals", alice)
+		assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+		resp.Body.Close()
+
+		resp = postJSONWithHeaders(t, approvalURL+"/approve", alice, map[string]interface{}{})
+		assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+		assert.Equal(t, parseProblem(t, resp).Code, "forbidden")
+	})
+
+	t.Run("Is approved by an operator", func(t *testing.T) {
+		resp := postJSON(t, approvalURL+"/approve", map[string]interface{}{})
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		resp.Body.Close()
+	})
+}
+
+func TestApproval_Errors(t *testing.T) {
+	server := setupTestServer(t)
+
+	fromID := openAccount(t, server.URL, "Alice", "5000")
+	toID := openAccount(t, server.URL, "Bob", "0")
+
+	tests := []struct {
+		name       string
+		resp       func() *http.Response
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name: "Unknown approval",
+			resp: func() *http.Response {
+				return getJSON(t, server.URL+"/v1/transfer-approvals/unknown")
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   "approval_not_found",
+		},
+		{
+			name: "Approving an unknown approval",
+			resp: func() *http.Response {
+				return postJSON(t, server.URL+"/v1/transfer-approvals/unknown/approve", map[string]interface{}{})
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   "approval_not_found",
+		},
+		{
+			name: "Invalid status filter",
+			resp: func() *http.Response {
+				return getJSON(t, server.URL+"/v1/transfer-approvals?status=done")
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation_failed",
+		},
+		{
+			name: "Request that would fail now",
+			resp: func() *http.Response {
+				return postJSON(t, server.URL+"/v1/transfer", map[string]interface{}{
+					"from_account_id": fromID,
+					"to_account_id":   toID,
+					"amount":          "9000",
+					"currency":        "USD",
+				})
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   "insufficient_funds",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Making the request
+			resp := tc.resp()
+
+			// Then: It should fail with the expected problem
+			assert.Equal(t, resp.StatusCode, tc.wantStatus)
+			assert.Equal(t, parseProblem(t, resp).Code, tc.wantCode)
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package integrationtest
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"gotest.tools/assert"
+)
+
+// issueKey has the test admin issue a key for subject acting in role.
+func issueKey(t *testing.T, serverURL, subject, role string) string {
+	t.Helper()
+
+	resp := postJSONWithHeaders(t, serverURL+"/v1/admin/api-keys", map[string]string{auth.APIKeyHeader: testAdminKey}, map[string]interface{}{
+		"subject": subject,
+		"role":    role,
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+	assert.Equal(t, resp.Header.Get("Cache-Control"), "no-store")
+
+	var issued map[string]string
+	parseJSON(t, resp, &issued)
+	assert.Equal(t, issued["subject"], subject)
+	assert.Equal(t, issued["role"], role)
+	assert.Assert(t, issued["api_key"] != "", "api_key should be disclosed once")
+
+	return issued["api_key"]
+}
+
+// createAccount opens an account for owner holding 1000 USD, as the test
+// operator.
+func createAccount(t *testing.T, serverURL, owner string) string {
+	t.Helper()
+
+	return openAccount(t, serverURL, owner, "1000")
+}
+
+func TestAuth_Unauthenticated(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "No API key", key: ""},
+		{name: "Unknown API key", key: "bk_unknown"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Listing accounts without valid credentials
+			resp := getJSONWithHeaders(t, server.URL+"/v1/accounts", map[string]string{auth.APIKeyHeader: tc.key})
+
+			// Then: The request should be rejected with a challenge
+			assert.Equal(t, resp.StatusCode, http.StatusUnauthorized)
+			assert.Equal(t, resp.Header.Get("WWW-Authenticate"), auth.Challenge)
+			assert.Equal(t, parseProblem(t, resp).Code, "unauthenticated")
+		})
+	}
+}
+
+func TestAuth_OpenAPIIsPublic(t *testing.T) {
+	server := setupTestServer(t)
+
+	// When: Fetching the OpenAPI document without credentials
+	resp := getJSONWithHeaders(t, server.URL+"/openapi.json", map[string]string{auth.APIKeyHeader: ""})
+	defer resp.Body.Close()
+
+	// Then: The document should be served
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+}
+
+func TestAuth_CustomerOnlyUsesOwnAccounts(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: A customer Alice, her account and Bob's
+	alice := map[string]string{auth.APIKeyHeader: issueKey(t, server.URL, "Alice", "customer")}
+	bobsID := createAccount(t, server.URL, "Bob")
+
+	resp := postJSONWithHeaders(t, server.URL+"/v1/accounts", alice, map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "0",
+		"currency":        "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+	var created map[string]string
+	parseJSON(t, resp, &created)
+	alicesID := created["account_id"]
+
+	// And: Cash paid in at the counter by an operator
+	resp = postJSON(t, server.URL+"/v1/accounts/"+alicesID+"/transactions", map[string]interface{}{
+		"type":     "deposit",
+		"amount":   "50",
+		"currency": "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+	resp.Body.Close()
+
+	t.Run("Reads her own account but cannot deposit to it", func(t *testing.T) {
+		resp := getJSONWithHeaders(t, server.URL+"/v1/accounts/"+alicesID, alice)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		resp.Body.Close()
+
+		resp = postJSONWithHeaders(t, server.URL+"/v1/accounts/"+alicesID+"/transactions", alice, map[string]interface{}{
+			"type":     "deposit",
+			"amount":   "1000000",
+			"currency": "USD",
+		})
+		assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+		assert.Equal(t, parseProblem(t, resp).Code, "forbidden")
+	})
+
+	t.Run("Lists only her own accounts", func(t *testing.T) {
+		resp := getJSONWithHeaders(t, server.URL+"/v1/accounts", alice)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		var page accountPage
+		parseJSON(t, resp, &page)
+		assert.Equal(t, page.Total, 1)
+		assert.Equal(t, page.Accounts[0].ID, alicesID)
+	})
+
+	t.Run("Transfers from her own account", func(t *testing.T) {
+		resp := postJSONWithHeaders(t, server.URL+"/v1/transfer", alice, map[string]interface{}{
+			"from_account_id": alicesID,
+			"to_account_id":   bobsID,
+			"amount":          "10",
+			"currency":        "USD",
+		})
+		assert.Equal(t, resp.StatusCode, http.StatusCreated)
+		resp.Body.Close()
+	})
+
+	forbidden := []struct {
+		name string
+		send func() *http.Response
+	}{
+		{name: "Open an account for someone else", send: func() *http.Response {
+			return postJSONWithHeaders(t, server.URL+"/v1/accounts", alice, map[string]interface{}{
+				"owner":           "Bob",
+				"initial_balance": "0",
+				"currency":        "USD",
+			})
+		}},
+		{name: "Open a funded account", send: func() *http.Response {
+			return postJSONWithHeaders(t, server.URL+"/v1/accounts", alice, map[string]interface{}{
+				"owner":           "Alice",
+				"initial_balance": "1000",
+				"currency":        "USD",
+			})
+		}},
+		{name: "Open an account with an overdraft", send: func() *http.Response {
+			return postJSONWithHeaders(t, server.URL+"/v1/accounts", alice, map[string]interface{}{
+				"owner":           "Alice",
+				"initial_balance": "0",
+				"overdraft_limit": "500",
+				"currency":        "USD",
+			})
+		}},
+		{name: "Read someone else's account", send: func() *http.Response {
+			return getJSONWithHeaders(t, server.URL+"/v1/accounts/"+bobsID, alice)
+		}},
+		{name: "Read someone else's transactions", send: func() *http.Response {
+			return getJSONWithHeaders(t, server.URL+"/v1/accounts/"+bobsID+"/transactions", alice)
+		}},
+		{name: "Withdraw from someone else's account", send: func() *http.Response {
+			return postJSONWithHeaders(t, server.URL+"/v1/accounts/"+bobsID+"/transactions", alice, map[string]interface{}{
+				"type":     "withdrawal",
+				"amount":   "10",
+				"currency": "USD",
+			})
+		}},
+		{name: "Transfer from someone else's account", send: func() *http.Response {
+			return postJSONWithHeaders(t, server.URL+"/v1/transfer", alice, map[string]interface{}{
+				"from_account_id": bobsID,
+				"to_account_id":   alicesID,
+				"amount":          "10",
+				"currency":        "USD",
+			})
+		}},
+		{name: "Freeze her own account", send: func() *http.Response {
+			return postJSONWithHeaders(t, server.URL+"/v1/accounts/"+alicesID+"/freeze", alice, map[string]interface{}{})
+		}},
+		{name: "Check the ledger", send: func() *http.Response {
+			return getJSONWithHeaders(t, server.URL+"/v1/ledger/check", alice)
+		}},
+		{name: "Issue an API key", send: func() *http.Response {
+			return postJSONWithHeaders(t, server.URL+"/v1/admin/api-keys", alice, map[string]interface{}{
+				"subject": "Alice",
+				"role":    "admin",
+			})
+		}},
+	}
+
+	for _, tc := range forbidden {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Alice acts beyond her own accounts
+			resp := tc.send()
+
+			// Then: The request should be forbidden
+			assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+			assert.Equal(t, parseProblem(t, resp).Code, "forbidden")
+		})
+	}
+}
+
+func TestAuth_OnlyAdminsIssueKeys(t *testing.T) {
+	server := setupTestServer(t)
+
+	// When: An operator tries to issue a key
+	resp := postJSON(t, server.URL+"/v1/admin/api-keys", map[string]interface{}{
+		"subject": "Mallory",
+		"role":    "admin",
+	})
+
+	// Then: The request should be forbidden
+	assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+	resp.Body.Close()
+
+	// When: The admin issues an operator key
+	operator := map[string]string{auth.APIKeyHeader: issueKey(t, server.URL, "Olivia", "operator")}
+
+	// Then: The key should authenticate its holder
+	resp = getJSONWithHeaders(t, server.URL+"/v1/ledger/check", operator)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	resp.Body.Close()
+}
+
+func TestAuth_IssueKeyInvalidRole(t *testing.T) {
+	server := setupTestServer(t)
+
+	// When: The admin issues a key with an unknown role
+	resp := postJSONWithHeaders(t, server.URL+"/v1/admin/api-keys", map[string]string{auth.APIKeyHeader: testAdminKey}, map[string]interface{}{
+		"subject": "Alice",
+		"role":    "superuser",
+	})
+
+	// Then: The request should be rejected
+	assert.Equal(t, resp.StatusCode, http.StatusBadRequest)
+	assert.Equal(t, parseProblem(t, resp).Code, "invalid_role")
+}
+
+// bearerToken returns the Authorization header of a token holding claims,
+// signed with testTokenKey.
+func bearerToken(t *testing.T, claims map[string]any) map[string]string {
+	t.Helper()
+
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		assert.NilError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(map[string]string{"alg": "EdDSA", "typ": "JWT"}) + "." + segment(claims)
+	signature := ed25519.Sign(testTokenKey, []byte(signed))
+
+	return map[string]string{"Authorization": "Bearer " + signed + "." + base64.RawURLEncoding.EncodeToString(signature)}
+}
+
+func TestAuth_BearerToken(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: Accounts of Carol, Bob and Dave, and a token for Carol that
+	// also grants her Bob's account
+	carolsID := createAccount(t, server.URL, "Carol")
+	bobsID := createAccount(t, server.URL, "Bob")
+	davesID := createAccount(t, server.URL, "Dave")
+	carol := bearerToken(t, map[string]any{
+		"sub":         "Carol",
+		"aud":         testTokenAudience,
+		"exp":         time.Now().Add(time.Hour).Unix(),
+		"roles":       []string{"customer"},
+		"account_ids": []string{bobsID},
+	})
+
+	t.Run("Lists her own and granted accounts", func(t *testing.T) {
+		resp := getJSONWithHeaders(t, server.URL+"/v1/accounts", carol)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		var page accountPage
+		parseJSON(t, resp, &page)
+		ids := make([]string, len(page.Accounts))
+		for i, account := range page.Accounts {
+			ids[i] = account.ID
+		}
+		slices.Sort(ids)
+		assert.DeepEqual(t, ids, slices.Sorted(slices.Values([]string{carolsID, bobsID})))
+	})
+
+	t.Run("Withdraws from a granted account", func(t *testing.T) {
+		resp := postJSONWithHeaders(t, server.URL+"/v1/accounts/"+bobsID+"/transactions", carol, map[string]interface{}{
+			"type":     "withdrawal",
+			"amount":   "10",
+			"currency": "USD",
+		})
+		assert.Equal(t, resp.StatusCode, http.StatusCreated)
+		resp.Body.Close()
+	})
+
+	t.Run("Cannot read other accounts", func(t *testing.T) {
+		resp := getJSONWithHeaders(t, server.URL+"/v1/accounts/"+davesID, carol)
+		assert.Equal(t, resp.StatusCode, http.StatusForbidden)
+		resp.Body.Close()
+	})
+
+	t.Run("Operator token", func(t *testing.T) {
+		operator := bearerToken(t, map[string]any{
+			"sub":   "Olivia",
+			"aud":   []string{"other", testTokenAudience},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"operator"},
+		})
+		resp := postJSONWithHeaders(t, server.URL+"/v1/accounts/"+davesID+"/freeze", operator, map[string]interface{}{})
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+		resp.Body.Close()
+	})
+}
+
+func TestAuth_InvalidBearerToken(t *testing.T) {
+	server := setupTestServer(t)
+
+	valid := map[string]any{
+		"sub":   "Carol",
+		"aud":   testTokenAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"customer"},
+	}
+	expired := maps.Clone(valid)
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	otherAudience := maps.Clone(valid)
+	otherAudience["aud"] = "other"
+	both := bearerToken(t, valid)
+	both[auth.APIKeyHeader] = testOperatorKey
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{name: "Expired", headers: bearerToken(t, expired)},
+		{name: "Other audience", headers: bearerToken(t, otherAudience)},
+		{name: "Not a JWT", headers: map[string]string{"Authorization": "Bearer not-a-token"}},
+		{name: "Unsupported scheme", headers: map[string]string{"Authorization": "Basic YWxpY2U6c2VjcmV0"}},
+		{name: "Both an API key and a token", headers: both},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Listing accounts with the credentials
+			resp := getJSONWithHeaders(t, server.URL+"/v1/accounts", tc.headers)
+
+			// Then: The request should be rejected with a challenge
+			assert.Equal(t, resp.StatusCode, http.StatusUnauthorized)
+			assert.Equal(t, resp.Header.Get("WWW-Authenticate"), auth.Challenge)
+			assert.Equal(t, parseProblem(t, resp).Code, "unauthenticated")
+		})
+	}
+}
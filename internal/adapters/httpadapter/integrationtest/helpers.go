@@ -5,36 +5,143 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/problem"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// Keys registered by newTestHandler. Requests are sent as the operator
+// unless a test chooses otherwise.
+const (
+	testOperatorKey = "test-operator-key"
+	testAdminKey    = "test-admin-key"
 )
 
+// accountPage is the response body of GET /accounts.
+type accountPage struct {
+	Accounts   []domain.Account `json:"accounts"`
+	NextCursor string           `json:"next_cursor"`
+	Total      int              `json:"total"`
+}
+
+// transactionPage is the response body of GET /accounts/{id}/transactions.
+type transactionPage struct {
+	Transactions []domain.Transaction `json:"transactions"`
+	NextCursor   string               `json:"next_cursor"`
+}
+
 func postJSON(t *testing.T, url string, body interface{}) *http.Response {
 	t.Helper()
 
+	return postJSONWithHeaders(t, url, nil, body)
+}
+
+func postJSONWithHeaders(t *testing.T, url string, headers map[string]string, body interface{}) *http.Response {
+	t.Helper()
+
+	return sendJSON(t, http.MethodPost, url, headers, body)
+}
+
+func putJSON(t *testing.T, url string, body interface{}) *http.Response {
+	t.Helper()
+
+	return sendJSON(t, http.MethodPut, url, nil, body)
+}
+
+func sendJSON(t *testing.T, method, url string, headers map[string]string, body interface{}) *http.Response {
+	t.Helper()
+
 	data, err := json.Marshal(body)
 	if err != nil {
 		t.Fatalf("failed to marshal request body: %v", err)
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(data))
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(data))
 	if err != nil {
-		t.Fatalf("failed to send POST request: %v", err)
+		t.Fatalf("failed to build %s request: %v", method, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
 	}
 
-	return resp
+	return send(t, req)
+}
+
+// postRaw sends body as is, for requests that are not valid JSON.
+func postRaw(t *testing.T, url, body string) *http.Response {
+	t.Helper()
+
+	return postRawAs(t, url, "application/json", body)
+}
+
+// postRawAs sends body as is, declared with the given Content-Type.
+func postRawAs(t *testing.T, url, contentType, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build POST request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	return send(t, req)
 }
 
 func getJSON(t *testing.T, url string) *http.Response {
 	t.Helper()
 
-	resp, err := http.Get(url)
+	return getJSONWithHeaders(t, url, nil)
+}
+
+func getJSONWithHeaders(t *testing.T, url string, headers map[string]string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("failed to build GET request: %v", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	return send(t, req)
+}
+
+// send sends req as the test operator, unless it already carries
+// credentials or opts out of them with an empty X-API-Key header.
+func send(t *testing.T, req *http.Request) *http.Response {
+	t.Helper()
+
+	_, hasAPIKey := req.Header[http.CanonicalHeaderKey(auth.APIKeyHeader)]
+	if _, hasAuthorization := req.Header["Authorization"]; !hasAPIKey && !hasAuthorization {
+		req.Header.Set(auth.APIKeyHeader, testOperatorKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		t.Fatalf("failed to send GET request: %v", err)
+		t.Fatalf("failed to send %s request: %v", req.Method, err)
 	}
 
 	return resp
 }
 
+// parseProblem decodes an RFC 7807 problem details response.
+func parseProblem(t *testing.T, resp *http.Response) problem.Details {
+	t.Helper()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != problem.ContentType {
+		t.Fatalf("expected Content-Type %q, got %q", problem.ContentType, contentType)
+	}
+
+	var details problem.Details
+	parseJSON(t, resp, &details)
+	return details
+}
+
 func parseJSON(t *testing.T, resp *http.Response, target interface{}) {
 	t.Helper()
 
@@ -0,0 +1,142 @@
+package integrationtest
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+func TestIdempotency_ReplaysTransaction(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "1000",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+	accountID := createResp["account_id"]
+
+	// When: The same withdrawal is sent twice with one Idempotency-Key
+	headers := map[string]string{httpadapter.IdempotencyKeyHeader: "withdraw-1"}
+	payload := map[string]interface{}{"type": "withdrawal", "amount": "100", "currency": "USD"}
+
+	first := postJSONWithHeaders(t, server.URL+"/v1/accounts/"+accountID+"/transactions", headers, payload)
+	second := postJSONWithHeaders(t, server.URL+"/v1/accounts/"+accountID+"/transactions", headers, payload)
+
+	// Then: Both responses are identical and only the second is a replay
+	assert.Equal(t, first.StatusCode, http.StatusCreated)
+	assert.Equal(t, second.StatusCode, http.StatusCreated)
+	assert.Equal(t, first.Header.Get(httpadapter.IdempotentReplayedHeader), "")
+	assert.Equal(t, second.Header.Get(httpadapter.IdempotentReplayedHeader), "true")
+
+	var firstTxn, secondTxn map[string]string
+	parseJSON(t, first, &firstTxn)
+	parseJSON(t, second, &secondTxn)
+	assert.Equal(t, firstTxn["transaction_id"], secondTxn["transaction_id"])
+
+	// And: The money was withdrawn only once
+	resp = getJSON(t, server.URL+"/v1/accounts/"+accountID)
+	var account domain.Account
+	parseJSON(t, resp, &account)
+	assert.DeepEqual(t, account.Balance, domain.MustParseMoney("900"))
+}
+
+func TestIdempotency_ConcurrentRetriesTransferOnce(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: Two accounts
+	fromResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "1000",
+		"currency":        "USD",
+	})
+	toResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Bob",
+		"initial_balance": "0",
+		"currency":        "USD",
+	})
+	var fromAccount, toAccount map[string]string
+	parseJSON(t, fromResp, &fromAccount)
+	parseJSON(t, toResp, &toAccount)
+
+	// When: A client fires the same transfer several times at once
+	headers := map[string]string{httpadapter.IdempotencyKeyHeader: "transfer-1"}
+	payload := map[string]interface{}{
+		"from_account_id": fromAccount["account_id"],
+		"to_account_id":   toAccount["account_id"],
+		"amount":          "100",
+		"currency":        "USD",
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := postJSONWithHeaders(t, server.URL+"/v1/transfer", headers, payload)
+			resp.Body.Close()
+			assert.Check(t, resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusConflict, resp.StatusCode)
+		}()
+	}
+	wg.Wait()
+
+	// Then: The transfer was executed exactly once
+	resp := getJSON(t, server.URL+"/v1/accounts/"+toAccount["account_id"])
+	var account domain.Account
+	parseJSON(t, resp, &account)
+	assert.DeepEqual(t, account.Balance, domain.MustParseMoney("100"))
+}
+
+func TestIdempotency_KeyReusedWithDifferentPayload(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account created with an Idempotency-Key
+	headers := map[string]string{httpadapter.IdempotencyKeyHeader: "create-1"}
+	resp := postJSONWithHeaders(t, server.URL+"/v1/accounts", headers, map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "1000",
+		"currency":        "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+	// When: The key is reused for a different account
+	resp = postJSONWithHeaders(t, server.URL+"/v1/accounts", headers, map[string]interface{}{
+		"owner":           "Bob",
+		"initial_balance": "1000",
+		"currency":        "USD",
+	})
+
+	// Then: The request is rejected
+	assert.Equal(t, resp.StatusCode, http.StatusUnprocessableEntity)
+
+	// And: Only the first account exists
+	resp = getJSON(t, server.URL+"/v1/accounts")
+	var page accountPage
+	parseJSON(t, resp, &page)
+	assert.Equal(t, page.Total, 1)
+}
+
+func TestIdempotency_ErrorResponsesAreReplayed(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: A deposit to a non-existent account, sent with an Idempotency-Key
+	headers := map[string]string{httpadapter.IdempotencyKeyHeader: "deposit-1"}
+	payload := map[string]interface{}{"type": "deposit", "amount": "100", "currency": "USD"}
+
+	first := postJSONWithHeaders(t, server.URL+"/v1/accounts/missing/transactions", headers, payload)
+
+	// When: It is retried
+	second := postJSONWithHeaders(t, server.URL+"/v1/accounts/missing/transactions", headers, payload)
+
+	// Then: The original client error is replayed
+	assert.Equal(t, first.StatusCode, http.StatusNotFound)
+	assert.Equal(t, second.StatusCode, http.StatusNotFound)
+	assert.Equal(t, second.Header.Get(httpadapter.IdempotentReplayedHeader), "true")
+}
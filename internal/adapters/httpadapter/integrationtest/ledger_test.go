@@ -0,0 +1,41 @@
+package integrationtest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+func TestCheckLedger(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account with a deposit
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "1000",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+
+	resp = postJSON(t, server.URL+"/v1/accounts/"+createResp["account_id"]+"/transactions", map[string]interface{}{
+		"type":     "deposit",
+		"amount":   "500",
+		"currency": "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+	// When: Running the ledger check
+	resp = getJSON(t, server.URL+"/v1/ledger/check")
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	// Then: The ledger should be balanced
+	var check domain.LedgerCheck
+	parseJSON(t, resp, &check)
+
+	assert.Assert(t, check.Balanced)
+	assert.Equal(t, check.EntryCount, 4)
+	assert.Assert(t, check.Totals["USD"].IsZero())
+}
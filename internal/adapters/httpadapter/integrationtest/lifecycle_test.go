@@ -0,0 +1,113 @@
+package integrationtest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+func TestAccountLifecycle(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account with a balance
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "100",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+	accountURL := server.URL + "/v1/accounts/" + createResp["account_id"]
+
+	withdraw := map[string]interface{}{
+		"type":     "withdrawal",
+		"amount":   "100",
+		"currency": "USD",
+	}
+
+	// When: Freezing the account
+	resp = postJSON(t, accountURL+"/freeze", nil)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	// Then: The response should contain the frozen account
+	var account domain.Account
+	parseJSON(t, resp, &account)
+	assert.Equal(t, account.Status, domain.StatusFrozen)
+
+	// And: Withdrawals should be locked
+	resp = postJSON(t, accountURL+"/transactions", withdraw)
+	assert.Equal(t, resp.StatusCode, http.StatusLocked)
+
+	// And: Freezing it again should conflict
+	resp = postJSON(t, accountURL+"/freeze", nil)
+	assert.Equal(t, resp.StatusCode, http.StatusConflict)
+
+	// When: Unfreezing the account and withdrawing its balance
+	resp = postJSON(t, accountURL+"/unfreeze", nil)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	resp = postJSON(t, accountURL+"/transactions", withdraw)
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+	// And: Closing it
+	resp = postJSON(t, accountURL+"/close", nil)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	// Then: The account should be closed
+	resp = getJSON(t, accountURL)
+	parseJSON(t, resp, &account)
+	assert.Equal(t, account.Status, domain.StatusClosed)
+
+	// And: Further operations should be rejected as gone
+	resp = postJSON(t, accountURL+"/transactions", map[string]interface{}{
+		"type":     "deposit",
+		"amount":   "100",
+		"currency": "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusGone)
+
+	resp = postJSON(t, accountURL+"/unfreeze", nil)
+	assert.Equal(t, resp.StatusCode, http.StatusGone)
+}
+
+func TestCloseAccount_Errors(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account with a balance
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "100",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+
+	tests := []struct {
+		name       string
+		accountID  string
+		wantStatus int
+	}{
+		{
+			name:       "Non-zero balance",
+			accountID:  createResp["account_id"],
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Non-existent account",
+			accountID:  "non-existent-id",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Closing the account
+			resp := postJSON(t, server.URL+"/v1/accounts/"+tc.accountID+"/close", nil)
+
+			// Then: The response should have the expected status
+			assert.Equal(t, resp.StatusCode, tc.wantStatus)
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package integrationtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter"
+)
+
+// spec is httpadapter.OpenAPISpec, parsed once for every conformance check.
+var spec = func() *openAPI {
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(httpadapter.OpenAPISpec))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		panic(fmt.Sprintf("invalid OpenAPI document: %v", err))
+	}
+	return &openAPI{doc: doc, exercised: make(map[string]bool)}
+}()
+
+// openAPI checks requests and responses against an OpenAPI 3.0 document.
+// It understands the subset of the specification the service's document
+// uses. It also remembers which operations answered successfully, so that
+// undocumented or untested routes stand out.
+type openAPI struct {
+	doc map[string]any
+
+	mu        sync.Mutex
+	exercised map[string]bool
+}
+
+// conformanceMiddleware fails t whenever a response, or a request the
+// server accepted, does not match the OpenAPI document. Rejected requests
+// are not checked, since tests send invalid ones on purpose.
+func conformanceMiddleware(t testing.TB, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request body: %v", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		rec := &teeWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		for _, violation := range spec.check(r, body, rec.status, rec.Header(), rec.body.Bytes()) {
+			t.Errorf("%s %s answered %d: %s", r.Method, r.URL.Path, rec.status, violation)
+		}
+	})
+}
+
+// check returns how an exchange deviates from the document. Deprecated
+// aliases are checked as the route their response names as successor.
+func (s *openAPI) check(r *http.Request, reqBody []byte, status int, header http.Header, respBody []byte) []string {
+	path := r.URL.Path
+	if successor, ok := successorVersion(header); ok {
+		path = successor.Path
+	}
+
+	pattern, pathItem, op := s.operation(r.Method, path)
+	if op == nil {
+		return []string{"operation is not documented"}
+	}
+
+	var violations []string
+	if status < http.StatusBadRequest {
+		s.mu.Lock()
+		s.exercised[r.Method+" "+pattern] = true
+		s.mu.Unlock()
+
+		violations = append(violations, s.checkQuery(pathItem, op, r.URL.Query())...)
+		// A request body that is not required may be left out
+		if requestBody, ok := op["requestBody"].(map[string]any); ok && (len(reqBody) > 0 || requestBody["required"] == true) {
+			violations = append(violations, s.checkContent("request", requestBody, r.Header.Get("Content-Type"), reqBody)...)
+		}
+	}
+
+	responses, _ := op["responses"].(map[string]any)
+	response, ok := responses[strconv.Itoa(status)].(map[string]any)
+	if !ok {
+		if response, ok = responses["default"].(map[string]any); !ok {
+			return append(violations, "status is not documented")
+		}
+	}
+	return append(violations, s.checkContent("response", response, header.Get("Content-Type"), respBody)...)
+}
+
+// successorVersion returns the target of a Link header with relation
+// successor-version, as set on deprecated aliases.
+func successorVersion(header http.Header) (*url.URL, bool) {
+	target, params, ok := strings.Cut(header.Get("Link"), ";")
+	if !ok || strings.TrimSpace(params) != `rel="successor-version"` {
+		return nil, false
+	}
+
+	u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+	return u, err == nil
+}
+
+// unexercised returns the documented operations no test has seen succeed.
+func (s *openAPI) unexercised() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var missing []string
+	paths, _ := s.doc["paths"].(map[string]any)
+	for pattern, item := range paths {
+		for method := range item.(map[string]any) {
+			if method == "parameters" {
+				continue
+			}
+			if key := strings.ToUpper(method) + " " + pattern; !s.exercised[key] {
+				missing = append(missing, key)
+			}
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// operation finds the documented operation serving method and path, along
+// with its path pattern and path item.
+func (s *openAPI) operation(method, path string) (string, map[string]any, map[string]any) {
+	paths, _ := s.doc["paths"].(map[string]any)
+	for pattern, item := range paths {
+		if !matchPath(pattern, path) {
+			continue
+		}
+		pathItem := item.(map[string]any)
+		if op, ok := pathItem[strings.ToLower(method)].(map[string]any); ok {
+			return pattern, pathItem, op
+		}
+	}
+	return "", nil, nil
+}
+
+// matchPath reports whether path is an instance of the path template
+// pattern, in which each {name} stands for one non-empty segment.
+func matchPath(pattern, path string) bool {
+	want := strings.Split(pattern, "/")
+	got := strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		isParam := strings.HasPrefix(want[i], "{") && strings.HasSuffix(want[i], "}")
+		if !(isParam && got[i] != "" || want[i] == got[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkQuery checks that every query parameter is documented and valid.
+func (s *openAPI) checkQuery(pathItem, op map[string]any, query url.Values) []string {
+	schemas := make(map[string]map[string]any)
+	for _, list := range []any{pathItem["parameters"], op["parameters"]} {
+		params, _ := list.([]any)
+		for _, p := range params {
+			param := s.resolve(p.(map[string]any))
+			if param["in"] == "query" {
+				schemas[param["name"].(string)], _ = param["schema"].(map[string]any)
+			}
+		}
+	}
+
+	var violations []string
+	for name, values := range query {
+		schema, ok := schemas[name]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("query parameter %q is not documented", name))
+			continue
+		}
+		for _, v := range values {
+			var value any = v
+			if s.resolve(schema)["type"] == "integer" {
+				value = json.Number(v)
+			}
+			violations = append(violations, s.validate(schema, value, "query parameter "+name)...)
+		}
+	}
+	return violations
+}
+
+// checkContent checks that a request or response body is of a media type
+// documented by its request body or response object, and that it matches
+// the schema of that media type.
+func (s *openAPI) checkContent(what string, object map[string]any, contentType string, body []byte) []string {
+	content, _ := s.resolve(object)["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := content[mediaType].(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("%s content type %q is not documented", what, contentType)}
+	}
+	schema, _ := media["schema"].(map[string]any)
+
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("%s body is not JSON: %v", what, err)}
+	}
+	return s.validate(schema, value, what)
+}
+
+// resolve follows a local $ref, if node is one.
+func (s *openAPI) resolve(node map[string]any) map[string]any {
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node
+	}
+
+	var target any = s.doc
+	for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		target = target.(map[string]any)[name]
+	}
+	return s.resolve(target.(map[string]any))
+}
+
+// validate returns how value deviates from schema; at names the value in
+// the messages.
+func (s *openAPI) validate(schema map[string]any, value any, at string) []string {
+	schema = s.resolve(schema)
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return []string{at + " must not be null"}
+	}
+
+	fail := func(format string, args ...any) []string {
+		return []string{at + " " + fmt.Sprintf(format, args...)}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fail("is %v, not one of %v", value, enum)
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fail("must be an object")
+		}
+		return s.validateObject(schema, obj, at)
+
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return fail("must be an array")
+		}
+		items, _ := schema["items"].(map[string]any)
+		var violations []string
+		for i, item := range arr {
+			violations = append(violations, s.validate(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return violations
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(str) {
+			return fail("%q does not match %s", str, pattern)
+		}
+		if maxLength, ok := schema["maxLength"].(json.Number); ok {
+			if n, _ := maxLength.Int64(); int64(len(str)) > n {
+				return fail("is longer than %d", n)
+			}
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fail("%q is not a date-time", str)
+			}
+		}
+
+	case "integer":
+		num, ok := value.(json.Number)
+		if !ok {
+			return fail("must be an integer")
+		}
+		n, err := num.Int64()
+		if err != nil {
+			return fail("%q is not an integer", num)
+		}
+		if minimum, ok := schema["minimum"].(json.Number); ok {
+			if low, _ := minimum.Int64(); n < low {
+				return fail("is less than %d", low)
+			}
+		}
+		if maximum, ok := schema["maximum"].(json.Number); ok {
+			if high, _ := maximum.Int64(); n > high {
+				return fail("is greater than %d", high)
+			}
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("must be a boolean")
+		}
+	}
+	return nil
+}
+
+func (s *openAPI) validateObject(schema, obj map[string]any, at string) []string {
+	var violations []string
+
+	required, _ := schema["required"].([]any)
+	for _, name := range required {
+		if _, ok := obj[name.(string)]; !ok {
+			violations = append(violations, fmt.Sprintf("%s.%s is required", at, name))
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	for name, v := range obj {
+		if property, ok := properties[name].(map[string]any); ok {
+			violations = append(violations, s.validate(property, v, at+"."+name)...)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				violations = append(violations, fmt.Sprintf("%s.%s is not documented", at, name))
+			}
+		case map[string]any:
+			violations = append(violations, s.validate(additional, v, at+"."+name)...)
+		}
+	}
+	return violations
+}
+
+// teeWriter passes a response through while keeping a copy of it.
+type teeWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	status      int
+	body        bytes.Buffer
+}
+
+func (tw *teeWriter) WriteHeader(code int) {
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		tw.status = code
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *teeWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	tw.body.Write(b)
+	return tw.ResponseWriter.Write(b)
+}
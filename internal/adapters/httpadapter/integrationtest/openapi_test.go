@@ -0,0 +1,26 @@
+package integrationtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestOpenAPI_Served(t *testing.T) {
+	server := setupTestServer(t)
+
+	// When: Fetching the OpenAPI document
+	resp := getJSON(t, server.URL+"/openapi.json")
+
+	// Then: It should describe the API as OpenAPI 3
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	parseJSON(t, resp, &doc)
+	assert.Equal(t, doc.OpenAPI[:2], "3.")
+	assert.Assert(t, len(doc.Paths) > 0)
+}
@@ -0,0 +1,113 @@
+package integrationtest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+func TestOverdraftLimit(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account created with an overdraft limit
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Acme",
+		"initial_balance": "100",
+		"overdraft_limit": "200",
+		"currency":        "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+	accountURL := server.URL + "/v1/accounts/" + createResp["account_id"]
+
+	// When: Withdrawing more than the balance
+	resp = postJSON(t, accountURL+"/transactions", map[string]interface{}{
+		"type":     "withdrawal",
+		"amount":   "250",
+		"currency": "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+	// Then: The account should show a negative balance and its limit
+	var account domain.Account
+	parseJSON(t, getJSON(t, accountURL), &account)
+	assert.DeepEqual(t, account.Balance, domain.MustParseMoney("-150"))
+	assert.DeepEqual(t, account.OverdraftLimit, domain.MustParseMoney("200"))
+
+	// When: Lowering the limit below the overdraft in use
+	resp = putJSON(t, accountURL+"/overdraft-limit", map[string]interface{}{
+		"overdraft_limit": "100",
+	})
+
+	// Then: The change should be rejected
+	assert.Equal(t, resp.StatusCode, http.StatusConflict)
+
+	// When: Raising the limit
+	resp = putJSON(t, accountURL+"/overdraft-limit", map[string]interface{}{
+		"overdraft_limit": "1000",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	// Then: The response should contain the updated account
+	parseJSON(t, resp, &account)
+	assert.DeepEqual(t, account.OverdraftLimit, domain.MustParseMoney("1000"))
+
+	// And: The audit trail should record both limits
+	resp = getJSON(t, accountURL+"/audit")
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	var events []domain.AuditEvent
+	parseJSON(t, resp, &events)
+	assert.Equal(t, len(events), 2)
+	assert.Equal(t, events[0].NewValue, "200.00")
+	assert.Equal(t, events[1].OldValue, "200.00")
+	assert.Equal(t, events[1].NewValue, "1000.00")
+}
+
+func TestOverdraftLimit_InvalidInput(t *testing.T) {
+	server := setupTestServer(t)
+
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Acme",
+		"initial_balance": "100",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+
+	tests := []struct {
+		name       string
+		request    map[string]interface{}
+		wantStatus int
+	}{
+		{
+			name:       "Missing limit",
+			request:    map[string]interface{}{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Negative limit",
+			request:    map[string]interface{}{"overdraft_limit": "-1"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Numeric limit",
+			request:    map[string]interface{}{"overdraft_limit": 100},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Setting the overdraft limit
+			resp := putJSON(t, server.URL+"/v1/accounts/"+createResp["account_id"]+"/overdraft-limit", tc.request)
+
+			// Then: The request should be rejected
+			assert.Equal(t, resp.StatusCode, tc.wantStatus)
+		})
+	}
+}
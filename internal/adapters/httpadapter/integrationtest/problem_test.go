@@ -0,0 +1,167 @@
+package integrationtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/problem"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/requestid"
+	"github.com/hesampakdaman/banking-service/internal/adapters/storage"
+	"gotest.tools/assert"
+)
+
+func TestProblem_DomainError(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account with a balance of 100
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "100",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+	path := "/v1/accounts/" + createResp["account_id"] + "/transactions"
+
+	// When: Withdrawing more than the balance, with a request ID
+	resp = postJSONWithHeaders(t, server.URL+path, map[string]string{requestid.Header: "req-42"}, map[string]interface{}{
+		"type":     "withdrawal",
+		"amount":   "500",
+		"currency": "USD",
+	})
+
+	// Then: The error should be described as problem details
+	assert.Equal(t, resp.StatusCode, http.StatusConflict)
+	assert.Equal(t, resp.Header.Get(requestid.Header), "req-42")
+	assert.DeepEqual(t, parseProblem(t, resp), problem.Details{
+		Type:      "about:blank",
+		Title:     "Conflict",
+		Status:    http.StatusConflict,
+		Detail:    "insufficient funds",
+		Instance:  path,
+		Code:      "insufficient_funds",
+		RequestID: "req-42",
+	})
+}
+
+func TestProblem_Codes(t *testing.T) {
+	server := setupTestServer(t)
+
+	tests := []struct {
+		name       string
+		send       func() *http.Response
+		wantStatus int
+		wantCode   string
+		wantFields []string
+	}{
+		{
+			name:       "Unknown account",
+			send:       func() *http.Response { return getJSON(t, server.URL+"/v1/accounts/non-existent-id") },
+			wantStatus: http.StatusNotFound,
+			wantCode:   "account_not_found",
+		},
+		{
+			name:       "Malformed JSON",
+			send:       func() *http.Response { return postRaw(t, server.URL+"/v1/accounts", `{"owner": `) },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_payload",
+		},
+		{
+			name: "Field of the wrong type",
+			send: func() *http.Response {
+				return postRaw(t, server.URL+"/v1/accounts", `{"owner": 42, "initial_balance": "1", "currency": "USD"}`)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation_failed",
+			wantFields: []string{"owner"},
+		},
+		{
+			name: "Invalid amount",
+			send: func() *http.Response {
+				return postRaw(t, server.URL+"/v1/accounts", `{"owner": "Alice", "initial_balance": "1.2.3", "currency": "USD"}`)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_money",
+		},
+		{
+			name: "Invalid transaction type",
+			send: func() *http.Response {
+				return postJSON(t, server.URL+"/v1/accounts/non-existent-id/transactions", map[string]interface{}{
+					"type": "refund", "amount": "1", "currency": "USD",
+				})
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation_failed",
+			wantFields: []string{"type"},
+		},
+		{
+			name:       "Invalid query parameter",
+			send:       func() *http.Response { return getJSON(t, server.URL+"/v1/accounts?limit=0") },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "validation_failed",
+			wantFields: []string{"limit"},
+		},
+		{
+			name:       "Invalid cursor",
+			send:       func() *http.Response { return getJSON(t, server.URL+"/v1/accounts?cursor=bogus") },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_cursor",
+		},
+		{
+			name: "Reused idempotency key",
+			send: func() *http.Response {
+				headers := map[string]string{httpadapter.IdempotencyKeyHeader: "key-1"}
+				resp := postJSONWithHeaders(t, server.URL+"/v1/accounts", headers, map[string]interface{}{
+					"owner": "Alice", "initial_balance": "1", "currency": "USD",
+				})
+				resp.Body.Close()
+				return postJSONWithHeaders(t, server.URL+"/v1/accounts", headers, map[string]interface{}{
+					"owner": "Bob", "initial_balance": "1", "currency": "USD",
+				})
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   "idempotency_key_reused",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Sending a request that fails
+			resp := tc.send()
+
+			// Then: The problem should carry a stable code and the request ID
+			assert.Equal(t, resp.StatusCode, tc.wantStatus)
+			details := parseProblem(t, resp)
+			assert.Equal(t, details.Status, tc.wantStatus)
+			assert.Equal(t, details.Code, tc.wantCode)
+			assert.Assert(t, details.RequestID != "")
+			assert.Equal(t, details.RequestID, resp.Header.Get(requestid.Header))
+
+			// And: Rejected fields should be named
+			var fields []string
+			for _, f := range details.Errors {
+				fields = append(fields, f.Field)
+				assert.Assert(t, f.Message != "")
+			}
+			assert.DeepEqual(t, fields, tc.wantFields)
+		})
+	}
+}
+
+func TestProblem_InternalErrorIsNotDisclosed(t *testing.T) {
+	// Given: A server whose storage fails
+	server := httptest.NewServer(newTestHandler(t, failingRepository{storage.NewMemoryRepository()}))
+	t.Cleanup(server.Close)
+
+	// When: Reading an account
+	resp := getJSON(t, server.URL+"/v1/accounts/some-id")
+
+	// Then: The problem should not reveal the underlying error
+	assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+	details := parseProblem(t, resp)
+	assert.Equal(t, details.Code, "internal_error")
+	assert.Assert(t, !strings.Contains(details.Detail, errStorage.Error()))
+}
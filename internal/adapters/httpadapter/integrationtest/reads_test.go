@@ -0,0 +1,128 @@
+package integrationtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"github.com/hesampakdaman/banking-service/internal/adapters/storage"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+	"gotest.tools/assert"
+)
+
+// failingRepository is a repository whose reads all fail, as a broken
+// storage backend's would.
+type failingRepository struct {
+	ports.Repository
+}
+
+var errStorage = errors.New("storage unavailable")
+
+func (failingRepository) GetAccount(context.Context, string) (domain.Account, error) {
+	return domain.Account{}, errStorage
+}
+
+func (failingRepository) QueryAccounts(context.Context, ports.AccountQuery) (ports.AccountPage, error) {
+	return ports.AccountPage{}, errStorage
+}
+
+func (failingRepository) QueryTransactions(context.Context, ports.TransactionQuery) (ports.TransactionPage, error) {
+	return ports.TransactionPage{}, errStorage
+}
+
+func (failingRepository) GetTransfer(context.Context, string) (domain.Transfer, error) {
+	return domain.Transfer{}, errStorage
+}
+
+func (failingRepository) LedgerSnapshot(context.Context) (ports.LedgerSnapshot, error) {
+	return ports.LedgerSnapshot{}, errStorage
+}
+
+func (failingRepository) ListAuditEvents(context.Context, string) ([]domain.AuditEvent, error) {
+	return nil, errStorage
+}
+
+// readPaths are the GET endpoints, for an account and transfer that do not
+// exist.
+var readPaths = []string{
+	"/v1/accounts",
+	"/v1/accounts/non-existent-id",
+	"/v1/accounts/non-existent-id/transactions",
+	"/v1/accounts/non-existent-id/audit",
+	"/v1/transfers/non-existent-id",
+	"/v1/ledger/check",
+}
+
+func TestReads_UnknownAccount(t *testing.T) {
+	server := setupTestServer(t)
+
+	for _, path := range []string{
+		"/v1/accounts/non-existent-id",
+		"/v1/accounts/non-existent-id/transactions",
+		"/v1/accounts/non-existent-id/audit",
+	} {
+		t.Run(path, func(t *testing.T) {
+			// When: Reading an account that does not exist
+			resp := getJSON(t, server.URL+path)
+			defer resp.Body.Close()
+
+			// Then: The response should indicate not found
+			assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+		})
+	}
+}
+
+func TestReads_StorageFailure(t *testing.T) {
+	// Given: A server whose storage fails every read
+	server := httptest.NewServer(newTestHandler(t, failingRepository{storage.NewMemoryRepository()}))
+	t.Cleanup(server.Close)
+
+	for _, path := range readPaths {
+		t.Run(path, func(t *testing.T) {
+			// When: Reading
+			resp := getJSON(t, server.URL+path)
+			defer resp.Body.Close()
+
+			// Then: The failure should be reported as a server error
+			assert.Equal(t, resp.StatusCode, http.StatusInternalServerError)
+		})
+	}
+}
+
+func TestReads_CancelledRequest(t *testing.T) {
+	handler := newTestHandler(t, storage.NewMemoryRepository())
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	t.Cleanup(cancelExpired)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected int
+	}{
+		{name: "Deadline exceeded", ctx: expired, expected: http.StatusGatewayTimeout},
+		{name: "Cancelled by client", ctx: cancelled, expected: 499},
+	}
+
+	for _, tc := range tests {
+		for _, path := range readPaths {
+			t.Run(tc.name+path, func(t *testing.T) {
+				// When: Reading with a context that is already done
+				req := httptest.NewRequestWithContext(tc.ctx, http.MethodGet, path, nil)
+				req.Header.Set(auth.APIKeyHeader, testOperatorKey)
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				// Then: The read should be abandoned rather than answered
+				assert.Equal(t, rec.Code, tc.expected)
+			})
+		}
+	}
+}
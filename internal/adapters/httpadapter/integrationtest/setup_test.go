@@ -1,25 +1,50 @@
 package integrationtest
 
 import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hesampakdaman/banking-service/internal/adapters/exchange"
 	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter"
+	"github.com/hesampakdaman/banking-service/internal/adapters/jwt"
 	"github.com/hesampakdaman/banking-service/internal/adapters/storage"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 	"github.com/hesampakdaman/banking-service/internal/service"
 )
 
+// TestMain also fails the suite when a documented operation was never seen
+// to succeed, unless only some tests were selected.
+func TestMain(m *testing.M) {
+	code := m.Run()
+
+	if run := flag.Lookup("test.run"); code == 0 && run.Value.String() == "" {
+		if missing := spec.unexercised(); len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "FAIL: operations without a successful example: %s\n", strings.Join(missing, ", "))
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
+
 func setupTestServer(t *testing.T) *httptest.Server {
 	t.Helper()
 
-	repo := storage.NewMemoryRepository()
-	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	bankService := service.NewBankService(repo, logger)
-	router := httpadapter.NewRouter(bankService)
-
-	testServer := httptest.NewServer(router)
+	testServer := httptest.NewServer(newTestHandler(t, storage.NewMemoryRepository()))
 
 	t.Cleanup(func() {
 		testServer.Close()
@@ -27,3 +52,70 @@ func setupTestServer(t *testing.T) *httptest.Server {
 
 	return testServer
 }
+
+// testApprovalThreshold is the largest transfer newTestHandler makes
+// without approval.
+var testApprovalThreshold = domain.ApprovalThreshold{Amount: domain.MustParseMoney("1000"), Currency: "USD"}
+
+// testTokenAudience is the audience of the bearer tokens accepted by
+// newTestHandler.
+const testTokenAudience = "banking-service"
+
+// testTokenKey signs the bearer tokens accepted by newTestHandler.
+var testTokenKey = func() ed25519.PrivateKey {
+	_, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		panic(err)
+	}
+	return private
+}()
+
+// newTestTokenVerifier accepts the bearer tokens signed with testTokenKey.
+func newTestTokenVerifier(t testing.TB, logger *slog.Logger) *jwt.Verifier {
+	t.Helper()
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"x":   base64.RawURLEncoding.EncodeToString(testTokenKey.Public().(ed25519.PublicKey)),
+	}}})
+	if err != nil {
+		t.Fatalf("failed to encode key set: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("failed to write key set: %v", err)
+	}
+
+	verifier, err := jwt.NewFileVerifier(path, testTokenAudience, logger)
+	if err != nil {
+		t.Fatalf("failed to load key set: %v", err)
+	}
+	return verifier
+}
+
+// newTestHandler wires the full HTTP stack on top of repo. Every exchange
+// is checked against the OpenAPI document. The keys testOperatorKey and
+// testAdminKey are registered in repo, bearer tokens signed with
+// testTokenKey are accepted, and transfers above testApprovalThreshold are
+// held for approval.
+func newTestHandler(t testing.TB, repo ports.Repository) http.Handler {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	usdToEUR, _ := domain.NewExchangeRate("USD", "EUR", domain.MustParseRate("0.92"), time.Now())
+	rates := exchange.NewStaticRateProvider(usdToEUR)
+	bankService := service.NewBankService(repo, rates, testApprovalThreshold, logger)
+	router := httpadapter.NewRouter(bankService)
+
+	for token, role := range map[string]domain.Role{testOperatorKey: domain.RoleOperator, testAdminKey: domain.RoleAdmin} {
+		if _, err := bankService.RegisterAPIKey(context.Background(), token, "test-"+string(role), role); err != nil {
+			t.Fatalf("failed to register %s key: %v", role, err)
+		}
+	}
+
+	handler := httpadapter.IdempotencyMiddleware(router, httpadapter.NewIdempotencyStore(time.Hour))
+	handler = httpadapter.AuthenticationMiddleware(handler, bankService, newTestTokenVerifier(t, logger), logger)
+
+	return conformanceMiddleware(t, httpadapter.RequestIDMiddleware(handler))
+}
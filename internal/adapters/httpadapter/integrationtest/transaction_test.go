@@ -13,9 +13,10 @@ func TestCreateTransaction_Withdrawal(t *testing.T) {
 	server := setupTestServer(t)
 
 	// Given: An account with sufficient balance
-	createResp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	createResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
 
 	var accountData map[string]string
@@ -23,9 +24,10 @@ func TestCreateTransaction_Withdrawal(t *testing.T) {
 	accountID := accountData["account_id"]
 
 	// When: A valid withdrawal transaction is created
-	resp := postJSON(t, server.URL+"/accounts/"+accountID+"/transactions", map[string]interface{}{
-		"type":   "withdrawal",
-		"amount": 200.0,
+	resp := postJSON(t, server.URL+"/v1/accounts/"+accountID+"/transactions", map[string]interface{}{
+		"type":     "withdrawal",
+		"amount":   "200",
+		"currency": "USD",
 	})
 
 	// Then: The response should contain the transaction ID
@@ -43,9 +45,10 @@ func TestCreateTransaction_Deposit(t *testing.T) {
 	server := setupTestServer(t)
 
 	// Given: An account with an initial balance
-	createResp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	createResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
 
 	var accountData map[string]string
@@ -53,9 +56,10 @@ func TestCreateTransaction_Deposit(t *testing.T) {
 	accountID := accountData["account_id"]
 
 	// When: A valid deposit transaction is created
-	resp := postJSON(t, server.URL+"/accounts/"+accountID+"/transactions", map[string]interface{}{
-		"type":   "deposit",
-		"amount": 500.0,
+	resp := postJSON(t, server.URL+"/v1/accounts/"+accountID+"/transactions", map[string]interface{}{
+		"type":     "deposit",
+		"amount":   "500",
+		"currency": "USD",
 	})
 
 	// Then: The response should contain the transaction ID
@@ -73,9 +77,10 @@ func TestCreateTransaction_Errors(t *testing.T) {
 	server := setupTestServer(t)
 
 	// Given: a single test account
-	resp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
 	var createResp map[string]string
 	parseJSON(t, resp, &createResp)
@@ -89,28 +94,43 @@ func TestCreateTransaction_Errors(t *testing.T) {
 	}{
 		{
 			name:       "Invalid Transaction Type",
-			payload:    map[string]interface{}{"type": "invalid_type", "amount": 100},
+			payload:    map[string]interface{}{"type": "invalid_type", "amount": "100", "currency": "USD"},
 			wantStatus: http.StatusBadRequest,
 		},
 		{
 			name:       "Non-Existent Account",
 			accountID:  "non-existent-id",
-			payload:    map[string]interface{}{"type": "deposit", "amount": 100},
+			payload:    map[string]interface{}{"type": "deposit", "amount": "100", "currency": "USD"},
 			wantStatus: http.StatusNotFound,
 		},
 		{
 			name:       "Insufficient Funds",
-			payload:    map[string]interface{}{"type": "withdrawal", "amount": 5000},
+			payload:    map[string]interface{}{"type": "withdrawal", "amount": "5000", "currency": "USD"},
 			wantStatus: http.StatusConflict,
 		},
 		{
 			name:       "Negative Amount",
-			payload:    map[string]interface{}{"type": "deposit", "amount": -100},
+			payload:    map[string]interface{}{"type": "deposit", "amount": "-100", "currency": "USD"},
 			wantStatus: http.StatusBadRequest,
 		},
 		{
 			name:       "Zero Amount",
-			payload:    map[string]interface{}{"type": "withdrawal", "amount": 0},
+			payload:    map[string]interface{}{"type": "withdrawal", "amount": "0", "currency": "USD"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Currency Mismatch",
+			payload:    map[string]interface{}{"type": "deposit", "amount": "100", "currency": "EUR"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Too Many Decimal Places",
+			payload:    map[string]interface{}{"type": "deposit", "amount": "10.001", "currency": "USD"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Amount Not a String",
+			payload:    map[string]interface{}{"type": "deposit", "amount": 10.5, "currency": "USD"},
 			wantStatus: http.StatusBadRequest,
 		},
 	}
@@ -122,7 +142,7 @@ func TestCreateTransaction_Errors(t *testing.T) {
 				accountID = testAccountID
 			}
 
-			resp := postJSON(t, server.URL+"/accounts/"+accountID+"/transactions", tc.payload)
+			resp := postJSON(t, server.URL+"/v1/accounts/"+accountID+"/transactions", tc.payload)
 			assert.Equal(t, resp.StatusCode, tc.wantStatus)
 		})
 	}
@@ -132,13 +152,15 @@ func TestTransfer_Success(t *testing.T) {
 	server := setupTestServer(t)
 
 	// Given: Two accounts
-	fromResp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	fromResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
-	toResp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	toResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Bob",
-		"initial_balance": 500,
+		"initial_balance": "500",
+		"currency":        "USD",
 	})
 
 	var fromAccount, toAccount map[string]string
@@ -149,10 +171,11 @@ func TestTransfer_Success(t *testing.T) {
 	toID := toAccount["account_id"]
 
 	// When: Transferring 200 from Alice → Bob
-	transferResp := postJSON(t, server.URL+"/transfer", map[string]interface{}{
+	transferResp := postJSON(t, server.URL+"/v1/transfer", map[string]interface{}{
 		"from_account_id": fromID,
 		"to_account_id":   toID,
-		"amount":          200,
+		"amount":          "200",
+		"currency":        "USD",
 	})
 
 	// Then: Verify the response contains transaction IDs
@@ -163,23 +186,52 @@ func TestTransfer_Success(t *testing.T) {
 
 	assert.Assert(t, txnResp["withdrawal_transaction_id"] != "", "Missing withdrawal transaction ID")
 	assert.Assert(t, txnResp["deposit_transaction_id"] != "", "Missing deposit transaction ID")
+
+	// And: The transfer can be traced from its ID to both legs
+	resp := getJSON(t, server.URL+"/v1/transfers/"+txnResp["transfer_id"])
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	var transfer domain.Transfer
+	parseJSON(t, resp, &transfer)
+
+	assert.Equal(t, transfer.ID, txnResp["transfer_id"])
+	assert.Equal(t, transfer.Withdrawal.ID, txnResp["withdrawal_transaction_id"])
+	assert.Equal(t, transfer.Withdrawal.AccountID, fromID)
+	assert.Equal(t, transfer.Withdrawal.CounterpartyID, toID)
+	assert.Equal(t, transfer.Withdrawal.TransferID, transfer.ID)
+	assert.Equal(t, transfer.Deposit.ID, txnResp["deposit_transaction_id"])
+	assert.Equal(t, transfer.Deposit.AccountID, toID)
+	assert.Equal(t, transfer.Deposit.CounterpartyID, fromID)
+	assert.Equal(t, transfer.Deposit.TransferID, transfer.ID)
+}
+
+func TestGetTransfer_NotFound(t *testing.T) {
+	server := setupTestServer(t)
+
+	// When: Retrieving a transfer that does not exist
+	resp := getJSON(t, server.URL+"/v1/transfers/non-existent-id")
+
+	// Then: The response should indicate not found
+	assert.Equal(t, resp.StatusCode, http.StatusNotFound)
 }
 
 func TestTransfer_Errors(t *testing.T) {
 	server := setupTestServer(t)
 
 	// Given: A valid accounts
-	validResp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	validResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
 	var validAccount map[string]string
 	parseJSON(t, validResp, &validAccount)
 	accID1 := validAccount["account_id"]
 
-	secondResp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	secondResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Bob",
-		"initial_balance": 500,
+		"initial_balance": "500",
+		"currency":        "USD",
 	})
 	var secondAccount map[string]string
 	parseJSON(t, secondResp, &secondAccount)
@@ -195,7 +247,8 @@ func TestTransfer_Errors(t *testing.T) {
 			payload: map[string]interface{}{
 				"from_account_id": "invalid-id",
 				"to_account_id":   accID1,
-				"amount":          100,
+				"amount":          "100",
+				"currency":        "USD",
 			},
 			wantStatus: http.StatusNotFound,
 		},
@@ -204,7 +257,8 @@ func TestTransfer_Errors(t *testing.T) {
 			payload: map[string]interface{}{
 				"from_account_id": accID1,
 				"to_account_id":   "invalid-id",
-				"amount":          100,
+				"amount":          "100",
+				"currency":        "USD",
 			},
 			wantStatus: http.StatusNotFound,
 		},
@@ -213,7 +267,8 @@ func TestTransfer_Errors(t *testing.T) {
 			payload: map[string]interface{}{
 				"from_account_id": accID1,
 				"to_account_id":   accID1,
-				"amount":          100,
+				"amount":          "100",
+				"currency":        "USD",
 			},
 			wantStatus: http.StatusBadRequest,
 		},
@@ -222,7 +277,8 @@ func TestTransfer_Errors(t *testing.T) {
 			payload: map[string]interface{}{
 				"from_account_id": accID1,
 				"to_account_id":   accID2,
-				"amount":          5000, // More than balance
+				"amount":          "5000", // More than balance
+				"currency":        "USD",
 			},
 			wantStatus: http.StatusConflict,
 		},
@@ -231,7 +287,8 @@ func TestTransfer_Errors(t *testing.T) {
 			payload: map[string]interface{}{
 				"from_account_id": accID1,
 				"to_account_id":   accID2,
-				"amount":          -50,
+				"amount":          "-50",
+				"currency":        "USD",
 			},
 			wantStatus: http.StatusBadRequest,
 		},
@@ -240,7 +297,8 @@ func TestTransfer_Errors(t *testing.T) {
 			payload: map[string]interface{}{
 				"from_account_id": accID1,
 				"to_account_id":   accID2,
-				"amount":          0,
+				"amount":          "0",
+				"currency":        "USD",
 			},
 			wantStatus: http.StatusBadRequest,
 		},
@@ -248,7 +306,7 @@ func TestTransfer_Errors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resp := postJSON(t, server.URL+"/transfer", tt.payload)
+			resp := postJSON(t, server.URL+"/v1/transfer", tt.payload)
 			assert.Equal(t, resp.StatusCode, tt.wantStatus)
 		})
 	}
@@ -258,38 +316,211 @@ func TestListTransactions(t *testing.T) {
 	server := setupTestServer(t)
 
 	// Given: A single test account
-	resp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
 		"owner":           "Alice",
-		"initial_balance": 1000,
+		"initial_balance": "1000",
+		"currency":        "USD",
 	})
 	var createResp map[string]string
 	parseJSON(t, resp, &createResp)
 	accountID := createResp["account_id"]
 
 	// And: A single deposit transaction
-	resp = postJSON(t, server.URL+"/accounts/"+accountID+"/transactions", map[string]interface{}{
-		"type":   "deposit",
-		"amount": 500,
+	resp = postJSON(t, server.URL+"/v1/accounts/"+accountID+"/transactions", map[string]interface{}{
+		"type":     "deposit",
+		"amount":   "500",
+		"currency": "USD",
 	})
 	var txnResp map[string]string
 	parseJSON(t, resp, &txnResp)
 	transactionID := txnResp["transaction_id"]
 
 	// When: Listing transactions for the account
-	resp = getJSON(t, server.URL+"/accounts/"+accountID+"/transactions")
+	resp = getJSON(t, server.URL+"/v1/accounts/"+accountID+"/transactions")
 
 	// Then: The response should contain the deposit transaction
-	var transactions []domain.Transaction
-	parseJSON(t, resp, &transactions)
+	var page transactionPage
+	parseJSON(t, resp, &page)
+	transactions := page.Transactions
+	assert.Equal(t, page.NextCursor, "")
 
 	expected := []domain.Transaction{
 		{
 			ID:        transactionID,
 			AccountID: accountID,
 			Type:      domain.Deposit,
-			Amount:    500.0,
+			Amount:    domain.MustParseMoney("500"),
+			Currency:  "USD",
 		},
 	}
 	transactions[0].Timestamp = time.Time{} // ignore time field
 	assert.DeepEqual(t, expected, transactions)
 }
+
+func TestListTransactions_PaginatedAndFiltered(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account with five deposits of increasing amounts and a withdrawal
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "0",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+	accountID := createResp["account_id"]
+
+	for _, amount := range []string{"10", "20", "30", "40", "50"} {
+		resp = postJSON(t, server.URL+"/v1/accounts/"+accountID+"/transactions", map[string]interface{}{
+			"type":     "deposit",
+			"amount":   amount,
+			"currency": "USD",
+		})
+		assert.Equal(t, resp.StatusCode, http.StatusCreated)
+		resp.Body.Close()
+	}
+	resp = postJSON(t, server.URL+"/v1/accounts/"+accountID+"/transactions", map[string]interface{}{
+		"type":     "withdrawal",
+		"amount":   "25",
+		"currency": "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+	resp.Body.Close()
+
+	// When: Paging through the deposits of at least 20, two at a time
+	var amounts []string
+	url := server.URL + "/v1/accounts/" + accountID + "/transactions?type=deposit&min_amount=20&limit=2"
+	next := url
+	for next != "" {
+		resp = getJSON(t, next)
+		assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+		var page transactionPage
+		parseJSON(t, resp, &page)
+		for _, txn := range page.Transactions {
+			amounts = append(amounts, txn.Amount.String())
+		}
+
+		next = ""
+		if page.NextCursor != "" {
+			next = url + "&cursor=" + page.NextCursor
+		}
+	}
+
+	// Then: Exactly the matching deposits should be returned, oldest first
+	assert.DeepEqual(t, amounts, []string{"20.00", "30.00", "40.00", "50.00"})
+}
+
+func TestListTransactions_InvalidQuery(t *testing.T) {
+	server := setupTestServer(t)
+
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "0",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+	accountID := createResp["account_id"]
+
+	tests := []struct {
+		name     string
+		query    string
+		expected int
+	}{
+		{name: "Invalid date", query: "from=yesterday", expected: http.StatusBadRequest},
+		{name: "Invalid type", query: "type=refund", expected: http.StatusBadRequest},
+		{name: "Invalid amount", query: "max_amount=ten", expected: http.StatusBadRequest},
+		{name: "Invalid limit", query: "limit=-1", expected: http.StatusBadRequest},
+		{name: "Invalid cursor", query: "cursor=bogus", expected: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Listing transactions with a malformed query parameter
+			resp := getJSON(t, server.URL+"/v1/accounts/"+accountID+"/transactions?"+tc.query)
+			defer resp.Body.Close()
+
+			// Then: It should be rejected
+			assert.Equal(t, resp.StatusCode, tc.expected)
+		})
+	}
+
+	// And: Listing transactions of an unknown account should return 404
+	resp = getJSON(t, server.URL+"/v1/accounts/non-existent-id/transactions")
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+}
+
+func TestTransfer_CrossCurrency(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: A USD and a EUR account
+	fromResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "1000",
+		"currency":        "USD",
+	})
+	toResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Bob",
+		"initial_balance": "0",
+		"currency":        "EUR",
+	})
+
+	var fromAccount, toAccount map[string]string
+	parseJSON(t, fromResp, &fromAccount)
+	parseJSON(t, toResp, &toAccount)
+
+	// When: Transferring 100 USD from Alice → Bob at 0.92
+	resp := postJSON(t, server.URL+"/v1/transfer", map[string]interface{}{
+		"from_account_id": fromAccount["account_id"],
+		"to_account_id":   toAccount["account_id"],
+		"amount":          "100",
+		"currency":        "USD",
+	})
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+
+	// Then: Bob's deposit leg is in EUR and records the conversion
+	resp = getJSON(t, server.URL+"/v1/accounts/"+toAccount["account_id"]+"/transactions")
+	var page transactionPage
+	parseJSON(t, resp, &page)
+	transactions := page.Transactions
+
+	assert.Equal(t, len(transactions), 1)
+	assert.DeepEqual(t, transactions[0].Amount, domain.MustParseMoney("92"))
+	assert.Equal(t, transactions[0].Currency, domain.Currency("EUR"))
+	assert.Assert(t, transactions[0].Conversion != nil, "deposit leg should record the conversion")
+	assert.DeepEqual(t, transactions[0].Conversion.Rate, domain.MustParseRate("0.92"))
+	assert.DeepEqual(t, transactions[0].Conversion.OriginalAmount, domain.MustParseMoney("100"))
+}
+
+func TestTransfer_NoExchangeRate(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: A EUR and a USD account, without a EUR→USD rate
+	fromResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "1000",
+		"currency":        "EUR",
+	})
+	toResp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Bob",
+		"initial_balance": "0",
+		"currency":        "USD",
+	})
+
+	var fromAccount, toAccount map[string]string
+	parseJSON(t, fromResp, &fromAccount)
+	parseJSON(t, toResp, &toAccount)
+
+	// When: Transferring between them
+	resp := postJSON(t, server.URL+"/v1/transfer", map[string]interface{}{
+		"from_account_id": fromAccount["account_id"],
+		"to_account_id":   toAccount["account_id"],
+		"amount":          "100",
+		"currency":        "EUR",
+	})
+
+	// Then: The transfer is rejected
+	assert.Equal(t, resp.StatusCode, http.StatusUnprocessableEntity)
+}
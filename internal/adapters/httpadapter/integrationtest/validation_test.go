@@ -0,0 +1,154 @@
+package integrationtest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRequestValidation(t *testing.T) {
+	server := setupTestServer(t)
+
+	// Given: An account to post transactions to
+	resp := postJSON(t, server.URL+"/v1/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "100",
+		"currency":        "USD",
+	})
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+	transactionsURL := server.URL + "/v1/accounts/" + createResp["account_id"] + "/transactions"
+
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantFields  []string
+	}{
+		{
+			name:        "Unknown field",
+			url:         server.URL + "/v1/accounts",
+			contentType: "application/json",
+			body:        `{"owner": "Alice", "initial_balance": "1", "currency": "USD", "nickname": "Al"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "validation_failed",
+			wantFields:  []string{"nickname"},
+		},
+		{
+			name:        "Field in the wrong case",
+			url:         server.URL + "/v1/accounts",
+			contentType: "application/json",
+			body:        `{"Owner": "Alice", "initial_balance": "1", "currency": "USD"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "validation_failed",
+			wantFields:  []string{"owner", "Owner"},
+		},
+		{
+			name:        "Missing fields are all reported",
+			url:         server.URL + "/v1/transfer",
+			contentType: "application/json",
+			body:        `{"amount": "1"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "validation_failed",
+			wantFields:  []string{"from_account_id", "to_account_id", "currency"},
+		},
+		{
+			name:        "Null required field",
+			url:         transactionsURL,
+			contentType: "application/json",
+			body:        `{"type": "deposit", "amount": null, "currency": "USD"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "validation_failed",
+			wantFields:  []string{"amount"},
+		},
+		{
+			name:        "Trailing data",
+			url:         transactionsURL,
+			contentType: "application/json",
+			body:        `{"type": "deposit", "amount": "1", "currency": "USD"} {}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "invalid_payload",
+		},
+		{
+			name:        "Not an object",
+			url:         transactionsURL,
+			contentType: "application/json",
+			body:        `["deposit", "1", "USD"]`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "invalid_payload",
+		},
+		{
+			name:        "Empty body",
+			url:         transactionsURL,
+			contentType: "application/json",
+			body:        ``,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "invalid_payload",
+		},
+		{
+			name:        "Body too large",
+			url:         server.URL + "/v1/accounts",
+			contentType: "application/json",
+			body:        `{"owner": "` + strings.Repeat("A", 128<<10) + `", "initial_balance": "1", "currency": "USD"}`,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantCode:    "request_too_large",
+		},
+		{
+			name:        "Wrong content type",
+			url:         transactionsURL,
+			contentType: "text/plain",
+			body:        `{"type": "deposit", "amount": "1", "currency": "USD"}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantCode:    "unsupported_media_type",
+		},
+		{
+			name:       "Missing content type",
+			url:        transactionsURL,
+			body:       `{"type": "deposit", "amount": "1", "currency": "USD"}`,
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantCode:   "unsupported_media_type",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Sending a request that breaks the rules
+			resp := postRawAs(t, tc.url, tc.contentType, tc.body)
+
+			// Then: It should be rejected before reaching the service
+			assert.Equal(t, resp.StatusCode, tc.wantStatus)
+			details := parseProblem(t, resp)
+			assert.Equal(t, details.Code, tc.wantCode)
+
+			var fields []string
+			for _, f := range details.Errors {
+				fields = append(fields, f.Field)
+			}
+			assert.DeepEqual(t, fields, tc.wantFields)
+		})
+	}
+
+	// And: The balance should be untouched
+	resp = getJSON(t, server.URL+"/v1/accounts/"+createResp["account_id"])
+	var account struct {
+		Balance string `json:"balance"`
+	}
+	parseJSON(t, resp, &account)
+	assert.Equal(t, account.Balance, "100.00")
+}
+
+func TestRequestValidation_ContentTypeParameters(t *testing.T) {
+	server := setupTestServer(t)
+
+	// When: Declaring the JSON body with a charset
+	resp := postRawAs(t, server.URL+"/v1/accounts", "application/json; charset=utf-8",
+		`{"owner": "Alice", "initial_balance": "1", "currency": "USD"}`)
+
+	// Then: The request should be accepted
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+}
@@ -0,0 +1,44 @@
+package integrationtest
+
+import (
+	"net/http"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLegacyPaths_AreDeprecatedAliases(t *testing.T) {
+	server := setupTestServer(t)
+
+	// When: Opening an account through the unversioned path
+	resp := postJSON(t, server.URL+"/accounts", map[string]interface{}{
+		"owner":           "Alice",
+		"initial_balance": "100",
+		"currency":        "USD",
+	})
+
+	// Then: It should work, but announce its deprecation and successor
+	assert.Equal(t, resp.StatusCode, http.StatusCreated)
+	assert.Assert(t, resp.Header.Get("Deprecation") != "")
+	_, err := http.ParseTime(resp.Header.Get("Sunset"))
+	assert.NilError(t, err)
+	assert.Equal(t, resp.Header.Get("Link"), `</v1/accounts>; rel="successor-version"`)
+
+	var createResp map[string]string
+	parseJSON(t, resp, &createResp)
+
+	// And: The account should be the same through either path
+	resp = getJSON(t, server.URL+"/v1/accounts/"+createResp["account_id"])
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	assert.Equal(t, resp.Header.Get("Deprecation"), "")
+	resp.Body.Close()
+
+	resp = getJSON(t, server.URL+"/accounts?owner=al&limit=1")
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	assert.Equal(t, resp.Header.Get("Link"), `</v1/accounts?owner=al&limit=1>; rel="successor-version"`)
+
+	var page accountPage
+	parseJSON(t, resp, &page)
+	assert.Equal(t, page.Total, 1)
+	assert.Equal(t, page.Accounts[0].ID, createResp["account_id"])
+}
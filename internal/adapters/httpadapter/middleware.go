@@ -1,11 +1,65 @@
 package httpadapter
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/auth"
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/requestid"
+	"github.com/hesampakdaman/banking-service/internal/domain"
 )
 
+// maxRequestIDLength bounds client-supplied request IDs.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware attaches an ID to every request, taken from the
+// X-Request-ID header if the client sent a usable one and generated
+// otherwise. The ID is echoed in the response header and carried in the
+// request context.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !validRequestID(id) {
+			id = domain.GetUUID()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
+// validRequestID reports whether id is non-empty, not too long and made of
+// printable ASCII only, so that it is safe to echo and log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// TimeoutMiddleware gives every request a deadline of timeout; handlers
+// answer requests that run past it with 504. A non-positive timeout leaves
+// requests without a deadline.
+func TimeoutMiddleware(next http.Handler, timeout time.Duration) http.Handler {
+	if timeout <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // LoggingMiddleware logs the details of incoming HTTP requests and responses.
 func LoggingMiddleware(next http.Handler, logger *slog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -16,8 +70,13 @@ func LoggingMiddleware(next http.Handler, logger *slog.Logger) http.Handler {
 			"method", r.Method,
 			"path", r.URL.Path,
 			"remote_addr", r.RemoteAddr,
+			"request_id", requestid.FromContext(r.Context()),
 		)
 
+		if principal, ok := auth.FromContext(r.Context()); ok {
+			reqLogger = reqLogger.With("principal", principal.Subject, "role", principal.Role)
+		}
+
 		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
 		next.ServeHTTP(rw, r)
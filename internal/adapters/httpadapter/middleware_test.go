@@ -0,0 +1,85 @@
+package httpadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/requestid"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string // empty if a new ID should be generated
+	}{
+		{name: "Client supplied ID", header: "req-123", expected: "req-123"},
+		{name: "Missing ID", header: ""},
+		{name: "Too long ID", header: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "ID with control characters", header: "req\t123"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A handler that records the request ID in its context
+			var seen string
+			handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = requestid.FromContext(r.Context())
+			}))
+
+			// When: A request is handled
+			req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+			if tc.header != "" {
+				req.Header.Set(requestid.Header, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			// Then: The same usable ID is in the context and the response
+			assert.Assert(t, seen != "")
+			assert.Equal(t, rec.Header().Get(requestid.Header), seen)
+			if tc.expected != "" {
+				assert.Equal(t, seen, tc.expected)
+			} else {
+				assert.Assert(t, seen != tc.header)
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		wantDeadline bool
+	}{
+		{name: "Timeout", timeout: time.Minute, wantDeadline: true},
+		{name: "No timeout", timeout: 0, wantDeadline: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A handler that records the deadline of its context
+			var deadline time.Time
+			var hasDeadline bool
+			handler := TimeoutMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deadline, hasDeadline = r.Context().Deadline()
+			}), tc.timeout)
+
+			// When: A request is handled
+			start := time.Now()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts", nil))
+
+			// Then: The request has a deadline only if a timeout is set
+			assert.Equal(t, hasDeadline, tc.wantDeadline)
+			if tc.wantDeadline {
+				assert.Assert(t, !deadline.Before(start.Add(tc.timeout)))
+			}
+		})
+	}
+}
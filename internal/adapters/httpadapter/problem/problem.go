@@ -0,0 +1,62 @@
+// Package problem writes error responses as RFC 7807 problem details.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/requestid"
+)
+
+// ContentType is the media type of problem details responses.
+const ContentType = "application/problem+json"
+
+// StatusClientClosedRequest is the de facto status for a request abandoned
+// by the client before the response was ready. The client never sees it,
+// but it keeps such requests apart from server errors in logs and metrics.
+const StatusClientClosedRequest = 499
+
+// Details is an RFC 7807 problem details object. Code is a stable,
+// machine-readable identifier of the problem that clients can switch on;
+// Detail is meant for humans and may change.
+type Details struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a single request field or query parameter was
+// rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Write responds to r with the problem identified by status and code.
+func Write(w http.ResponseWriter, r *http.Request, status int, code, detail string, fields ...FieldError) {
+	title := http.StatusText(status)
+	if status == StatusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+
+	details := Details{
+		Type:      "about:blank",
+		Title:     title,
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: requestid.FromContext(r.Context()),
+		Errors:    fields,
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(details)
+}
@@ -0,0 +1,21 @@
+// Package requestid carries the ID of the HTTP request being handled, so
+// that it can be reported in error responses and logs.
+package requestid
+
+import "context"
+
+// Header is the request and response header carrying the request ID.
+const Header = "X-Request-ID"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
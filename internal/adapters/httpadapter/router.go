@@ -1,22 +1,99 @@
 package httpadapter
 
 import (
+	_ "embed"
 	"net/http"
 
 	"github.com/hesampakdaman/banking-service/internal/adapters/httpadapter/handlers"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 	"github.com/hesampakdaman/banking-service/internal/service"
 )
 
-func NewRouter(bankService *service.BankService) *http.ServeMux {
-	handler := handlers.NewHTTPHandler(bankService)
+// OpenAPISpec is the OpenAPI 3 description of the routes served by
+// NewRouter.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+// route is an endpoint of the API: its method and path pattern, as
+// understood by http.ServeMux, and its handler. Routes that predate
+// versioning are also served at their unversioned path, as deprecated
+// aliases that are not described in OpenAPISpec. Only public routes may
+// be called without credentials.
+type route struct {
+	method      string
+	path        string
+	handler     http.HandlerFunc
+	unversioned bool
+	deprecated  bool
+	public      bool
+}
 
+func NewRouter(bankService *service.BankService) *http.ServeMux {
 	mux := http.NewServeMux()
-	mux.HandleFunc("POST /accounts", handler.CreateAccountHandler)
-	mux.HandleFunc("GET /accounts/{id}", handler.GetAccountHandler)
-	mux.HandleFunc("GET /accounts", handler.ListAccountsHandler)
-	mux.HandleFunc("POST /accounts/{id}/transactions", handler.CreateTransactionHandler)
-	mux.HandleFunc("GET /accounts/{id}/transactions", handler.ListTransactionsHandler)
-	mux.HandleFunc("POST /transfer", handler.TransferHandler)
+	for _, r := range routes(bankService) {
+		if !r.public {
+			r.handler = requireAuthentication(r.handler)
+		}
+		mux.HandleFunc(r.method+" "+r.path, r.handler)
+	}
 
 	return mux
 }
+
+// routes lists every endpoint NewRouter serves: the OpenAPI document, the
+// routes of each API version under its prefix, and the unversioned aliases
+// of the routes of legacyVersion that had them.
+func routes(bankService ports.BankService) []route {
+	all := []route{{method: http.MethodGet, path: "/openapi.json", handler: serveOpenAPISpec, public: true}}
+
+	for _, v := range apiVersions {
+		for _, r := range v.routes(bankService) {
+			r.path = v.prefix + r.path
+			all = append(all, r)
+		}
+	}
+
+	for _, r := range legacyVersion.routes(bankService) {
+		if !r.unversioned {
+			continue
+		}
+		r.handler = deprecatedAlias(r.handler, legacyVersion.prefix)
+		r.deprecated = true
+		all = append(all, r)
+	}
+
+	return all
+}
+
+// v1Routes are the routes of version 1 of the API.
+func v1Routes(bankService ports.BankService) []route {
+	handler := handlers.NewHTTPHandler(bankService)
+
+	return []route{
+		{method: http.MethodPost, path: "/accounts", handler: handler.CreateAccountHandler, unversioned: true},
+		{method: http.MethodGet, path: "/accounts/{id}", handler: handler.GetAccountHandler, unversioned: true},
+		{method: http.MethodGet, path: "/accounts", handler: handler.ListAccountsHandler, unversioned: true},
+		{method: http.MethodPost, path: "/accounts/{id}/freeze", handler: handler.FreezeAccountHandler, unversioned: true},
+		{method: http.MethodPost, path: "/accounts/{id}/unfreeze", handler: handler.UnfreezeAccountHandler, unversioned: true},
+		{method: http.MethodPost, path: "/accounts/{id}/close", handler: handler.CloseAccountHandler, unversioned: true},
+		{method: http.MethodPut, path: "/accounts/{id}/overdraft-limit", handler: handler.SetOverdraftLimitHandler, unversioned: true},
+		{method: http.MethodGet, path: "/accounts/{id}/audit", handler: handler.ListAuditEventsHandler, unversioned: true},
+		{method: http.MethodPost, path: "/accounts/{id}/transactions", handler: handler.CreateTransactionHandler, unversioned: true},
+		{method: http.MethodGet, path: "/accounts/{id}/transactions", handler: handler.ListTransactionsHandler, unversioned: true},
+		{method: http.MethodPost, path: "/transfer", handler: handler.TransferHandler, unversioned: true},
+		{method: http.MethodGet, path: "/transfers/{id}", handler: handler.GetTransferHandler, unversioned: true},
+		{method: http.MethodGet, path: "/transfer-approvals", handler: handler.ListTransferApprovalsHandler},
+		{method: http.MethodGet, path: "/transfer-approvals/{id}", handler: handler.GetTransferApprovalHandler},
+		{method: http.MethodPost, path: "/transfer-approvals/{id}/approve", handler: handler.ApproveTransferHandler},
+		{method: http.MethodPost, path: "/transfer-approvals/{id}/reject", handler: handler.RejectTransferHandler},
+		{method: http.MethodGet, path: "/ledger/check", handler: handler.CheckLedgerHandler, unversioned: true},
+		{method: http.MethodPost, path: "/admin/api-keys", handler: handler.IssueAPIKeyHandler},
+	}
+}
+
+func serveOpenAPISpec(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(OpenAPISpec)
+}
@@ -0,0 +1,47 @@
+package httpadapter
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRoutesMatchOpenAPISpec(t *testing.T) {
+	// Given: The OpenAPI document
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	assert.NilError(t, json.Unmarshal(OpenAPISpec, &doc))
+
+	var documented []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			if method != "parameters" {
+				documented = append(documented, strings.ToUpper(method)+" "+path)
+			}
+		}
+	}
+
+	// When: Walking every route of the router
+	var served, aliases []string
+	for _, r := range routes(nil) {
+		if r.deprecated {
+			aliases = append(aliases, r.method+" "+legacyVersion.prefix+r.path)
+			continue
+		}
+		served = append(served, r.method+" "+r.path)
+	}
+
+	// Then: Each route should be documented, and each operation served
+	slices.Sort(documented)
+	slices.Sort(served)
+	assert.DeepEqual(t, served, documented)
+
+	// And: Each deprecated alias should stand for a documented route
+	for _, alias := range aliases {
+		assert.Assert(t, slices.Contains(documented, alias), alias)
+	}
+}
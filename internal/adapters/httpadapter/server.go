@@ -0,0 +1,75 @@
+package httpadapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ServerTimeouts bound how long the server waits on clients; zero leaves a
+// timeout unset.
+type ServerTimeouts struct {
+	// ReadHeader bounds reading a request's headers.
+	ReadHeader time.Duration
+	// Read bounds reading a whole request, body included.
+	Read time.Duration
+	// Write bounds handling a request and writing its response, counted
+	// from the end of its headers.
+	Write time.Duration
+	// Idle bounds how long a keep-alive connection waits for its next
+	// request.
+	Idle time.Duration
+}
+
+// NewServer returns a server for handler at addr, with the given
+// timeouts.
+func NewServer(addr string, handler http.Handler, timeouts ServerTimeouts, logger *slog.Logger) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: timeouts.ReadHeader,
+		ReadTimeout:       timeouts.Read,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+}
+
+// Serve serves requests on ln until ctx is done, then shuts server down
+// gracefully: it stops accepting connections and waits up to drainTimeout
+// for the requests in flight to complete. Requests still running after
+// that are cut off, and Serve fails.
+func Serve(ctx context.Context, server *http.Server, ln net.Listener, drainTimeout time.Duration, logger *slog.Logger) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down, draining requests in flight", "timeout", drainTimeout)
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(drainCtx); err != nil {
+		logger.Error("Requests still in flight after the drain timeout, closing connections", "error", err.Error())
+		_ = server.Close()
+		return fmt.Errorf("draining requests: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	logger.Info("All requests drained")
+	return nil
+}
@@ -0,0 +1,130 @@
+package httpadapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+// startServer serves handler on a free local port until the returned
+// context is cancelled, draining for at most drainTimeout. The result of
+// Serve is sent on the returned channel.
+func startServer(t *testing.T, handler http.Handler, drainTimeout time.Duration) (url string, stop context.CancelFunc, done <-chan error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(ln.Addr().String(), handler, ServerTimeouts{ReadHeader: time.Second}, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- Serve(ctx, server, ln, drainTimeout, logger)
+	}()
+
+	return "http://" + ln.Addr().String(), cancel, result
+}
+
+// blockingHandler answers requests once release is closed, after
+// signalling on started, unless it is full, that a request arrived.
+func blockingHandler(started chan<- struct{}, release <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestServe_DrainsRequestsInFlight(t *testing.T) {
+	// Given: A server with a request in flight
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	url, stop, done := startServer(t, blockingHandler(started, release), 5*time.Second)
+
+	inFlight := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Errorf("in-flight request failed: %v", err)
+		}
+		inFlight <- resp
+	}()
+	<-started
+
+	// When: The server is asked to stop
+	stop()
+
+	// Then: It stops accepting connections
+	assert.Assert(t, waitForRefusal(url), "server still accepts connections")
+
+	// And: The request in flight still completes
+	close(release)
+	resp := <-inFlight
+	assert.Assert(t, resp != nil)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	resp.Body.Close()
+
+	// And: The server stops cleanly
+	select {
+	case err := <-done:
+		assert.NilError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestServe_DrainTimeout(t *testing.T) {
+	// Given: A server with a request in flight that does not complete
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	defer close(release)
+	url, stop, done := startServer(t, blockingHandler(started, release), 50*time.Millisecond)
+
+	go func() {
+		if resp, err := http.Get(url); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	// When: The server is asked to stop
+	stop()
+
+	// Then: It gives up on the request once the drain timeout passes
+	select {
+	case err := <-done:
+		assert.Assert(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+// waitForRefusal reports whether new connections to url are refused
+// within a second.
+func waitForRefusal(url string) bool {
+	client := &http.Client{Timeout: 100 * time.Millisecond}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := client.Get(url)
+		if err != nil {
+			return true
+		}
+		resp.Body.Close()
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package httpadapter
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+// apiVersion is a set of routes mounted under a common path prefix. Every
+// version builds its own handlers on the same BankService, so a new
+// version can change request and response formats while clients of the
+// older ones keep being served.
+type apiVersion struct {
+	prefix string
+	routes func(ports.BankService) []route
+}
+
+// apiVersions are the versions of the API, in the order they were
+// introduced.
+var apiVersions = []apiVersion{
+	{prefix: "/v1", routes: v1Routes},
+}
+
+// legacyVersion is the version whose routes are also served without a
+// prefix, as the API was before it was versioned. Those aliases are
+// deprecated and will be removed at legacySunset.
+var legacyVersion = apiVersions[0]
+
+var (
+	legacyDeprecation = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacySunset      = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// canonicalPath returns the versioned path of a request: paths under a
+// version prefix are returned as they are, and anything else is taken as
+// an unversioned alias of legacyVersion.
+func canonicalPath(path string) string {
+	for _, v := range apiVersions {
+		if strings.HasPrefix(path, v.prefix+"/") {
+			return path
+		}
+	}
+	return legacyVersion.prefix + path
+}
+
+// deprecatedAlias serves an unversioned alias of a route of the version
+// mounted at prefix. Responses announce the deprecation (RFC 9745) and the
+// sunset (RFC 8594) of the alias, and link to the versioned route that
+// replaces it.
+func deprecatedAlias(next http.HandlerFunc, prefix string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		successor := prefix + r.URL.Path
+		if r.URL.RawQuery != "" {
+			successor += "?" + r.URL.RawQuery
+		}
+
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(legacyDeprecation.Unix(), 10))
+		w.Header().Set("Sunset", legacySunset.Format(http.TimeFormat))
+		w.Header().Set("Link", "<"+successor+`>; rel="successor-version"`)
+		next(w, r)
+	}
+}
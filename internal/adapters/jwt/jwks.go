@@ -0,0 +1,164 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// minRSABits is the smallest RSA modulus accepted in a key set.
+const minRSABits = 2048
+
+// key is a verification key of a key set. alg is the only algorithm
+// tokens signed with it may use.
+type key struct {
+	id     string
+	alg    string
+	public crypto.PublicKey
+}
+
+// jwk is a JSON Web Key as found in a key set (RFC 7517), restricted to
+// the members of the key types supported here.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseKeySet decodes a JWKS document. Keys that are not meant for
+// signatures are skipped; any other key that cannot be used is an error,
+// so that a broken key set is noticed rather than partially loaded.
+func parseKeySet(data []byte) ([]key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decoding key set: %w", err)
+	}
+
+	keys := make([]key, 0, len(set.Keys))
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		parsed, err := k.parse()
+		if err != nil {
+			return nil, fmt.Errorf("key %d (%q): %w", i, k.Kid, err)
+		}
+		keys = append(keys, parsed)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("key set holds no signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jwk) parse() (key, error) {
+	switch k.Kty {
+	case "RSA":
+		return k.parseRSA()
+	case "EC":
+		return k.parseEC()
+	case "OKP":
+		return k.parseOKP()
+	default:
+		return key{}, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func (k jwk) parseRSA() (key, error) {
+	if err := k.checkAlg("RS256"); err != nil {
+		return key{}, err
+	}
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return key{}, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := decodeInt(k.E)
+	if err != nil {
+		return key{}, fmt.Errorf("exponent: %w", err)
+	}
+	if n.BitLen() < minRSABits {
+		return key{}, fmt.Errorf("modulus of %d bits is shorter than %d", n.BitLen(), minRSABits)
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return key{}, errors.New("invalid exponent")
+	}
+
+	return key{id: k.Kid, alg: "RS256", public: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+}
+
+func (k jwk) parseEC() (key, error) {
+	if err := k.checkAlg("ES256"); err != nil {
+		return key{}, err
+	}
+	if k.Crv != "P-256" {
+		return key{}, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(x) != 32 {
+		return key{}, errors.New("invalid x coordinate")
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil || len(y) != 32 {
+		return key{}, errors.New("invalid y coordinate")
+	}
+
+	// Reject points that are not on the curve
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+		return key{}, fmt.Errorf("invalid point: %w", err)
+	}
+
+	public := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	return key{id: k.Kid, alg: "ES256", public: public}, nil
+}
+
+func (k jwk) parseOKP() (key, error) {
+	if err := k.checkAlg("EdDSA"); err != nil {
+		return key{}, err
+	}
+	if k.Crv != "Ed25519" {
+		return key{}, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil || len(x) != ed25519.PublicKeySize {
+		return key{}, errors.New("invalid public key")
+	}
+
+	return key{id: k.Kid, alg: "EdDSA", public: ed25519.PublicKey(x)}, nil
+}
+
+// checkAlg fails if the key declares an algorithm other than the one its
+// type is used with.
+func (k jwk) checkAlg(alg string) error {
+	if k.Alg != "" && k.Alg != alg {
+		return fmt.Errorf("unsupported algorithm %q for key type %s", k.Alg, k.Kty)
+	}
+	return nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid encoding")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,263 @@
+// Package jwt authenticates callers by the JSON Web Tokens issued to them
+// by a gateway, verified against the keys of a local JWKS file.
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// leeway tolerates clock skew between the gateway and this service when
+// checking exp and nbf.
+const leeway = 30 * time.Second
+
+// Verifier authenticates JWTs signed with one of the keys of a JWKS file.
+// The file is read again when it changes, so keys can be rotated without
+// a restart; if the new file cannot be used, the keys loaded before stay
+// in effect.
+//
+// A token must be signed with RS256, ES256 or EdDSA, carry the audience
+// the verifier was created for, and be within its exp and nbf. Its claims
+// map to the principal: sub is the subject, the most privileged of the
+// known roles in roles is the role, and account_ids are the accounts the
+// caller may use besides those it owns.
+type Verifier struct {
+	path     string
+	audience string
+	logger   *slog.Logger
+
+	mu      sync.RWMutex
+	keys    []key
+	modTime time.Time
+	size    int64
+}
+
+// NewFileVerifier loads the key set at path; it fails if the file is
+// missing or holds no usable key.
+func NewFileVerifier(path, audience string, logger *slog.Logger) (*Verifier, error) {
+	if audience == "" {
+		return nil, errors.New("an audience is required to verify tokens")
+	}
+
+	v := &Verifier{path: path, audience: audience, logger: logger}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key set: %w", err)
+	}
+	if err := v.load(info); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// claims are the registered and private claims of a token that are
+// checked or mapped to the principal.
+type claims struct {
+	Subject    string       `json:"sub"`
+	Audience   stringList   `json:"aud"`
+	ExpiresAt  *numericDate `json:"exp"`
+	NotBefore  *numericDate `json:"nbf"`
+	Roles      stringList   `json:"roles"`
+	AccountIDs []string     `json:"account_ids"`
+}
+
+// Authenticate returns the principal token was issued to. Tokens that
+// fail verification are rejected with an error wrapping
+// domain.ErrUnauthenticated.
+func (v *Verifier) Authenticate(ctx context.Context, token string) (domain.Principal, error) {
+	c, err := v.verify(token)
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %v", domain.ErrUnauthenticated, err)
+	}
+
+	var principal domain.Principal
+	for _, role := range []domain.Role{domain.RoleAdmin, domain.RoleOperator, domain.RoleCustomer} {
+		if slices.Contains(c.Roles, string(role)) {
+			principal = domain.Principal{Subject: c.Subject, Role: role, AccountIDs: c.AccountIDs}
+			break
+		}
+	}
+	if principal.Role == "" {
+		return domain.Principal{}, fmt.Errorf("%w: token grants no known role", domain.ErrUnauthenticated)
+	}
+
+	return principal, nil
+}
+
+func (v *Verifier) verify(token string) (claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims{}, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return claims{}, fmt.Errorf("malformed header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims{}, errors.New("malformed signature")
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	if !v.verifySignature(header.Alg, header.Kid, signed, signature) {
+		return claims{}, errors.New("invalid signature")
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return claims{}, fmt.Errorf("malformed claims: %w", err)
+	}
+
+	now := domain.GetTimeNow()
+	switch {
+	case c.Subject == "":
+		return claims{}, errors.New("token has no subject")
+	case c.ExpiresAt == nil:
+		return claims{}, errors.New("token has no expiry")
+	case !now.Before(c.ExpiresAt.Time().Add(leeway)):
+		return claims{}, errors.New("token has expired")
+	case c.NotBefore != nil && now.Add(leeway).Before(c.NotBefore.Time()):
+		return claims{}, errors.New("token is not valid yet")
+	case !slices.Contains(c.Audience, v.audience):
+		return claims{}, errors.New("token is meant for another audience")
+	}
+
+	return c, nil
+}
+
+// verifySignature reports whether one of the keys for alg, narrowed down
+// to kid if the token names one, signed signed.
+func (v *Verifier) verifySignature(alg, kid string, signed, signature []byte) bool {
+	for _, k := range v.currentKeys() {
+		if k.alg != alg || (kid != "" && k.id != kid) {
+			continue
+		}
+		if verifyWith(k, signed, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+func verifyWith(k key, signed, signature []byte) bool {
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		if len(signature) != 64 {
+			return false
+		}
+		digest := sha256.Sum256(signed)
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(public, digest[:], r, s)
+	case ed25519.PublicKey:
+		return ed25519.Verify(public, signed, signature)
+	default:
+		return false
+	}
+}
+
+// currentKeys returns the key set, reloading it first if the file was
+// changed since it was last read.
+func (v *Verifier) currentKeys() []key {
+	info, err := os.Stat(v.path)
+
+	v.mu.RLock()
+	keys, changed := v.keys, err == nil && (!info.ModTime().Equal(v.modTime) || info.Size() != v.size)
+	v.mu.RUnlock()
+
+	if changed {
+		if err := v.load(info); err != nil {
+			v.logger.Warn("Keeping previous key set", "path", v.path, "error", err.Error())
+		}
+		v.mu.RLock()
+		keys = v.keys
+		v.mu.RUnlock()
+	}
+
+	return keys
+}
+
+// load reads the key set. The file's modification time and size are
+// recorded even if it cannot be used, so that it is only read again once
+// it changes.
+func (v *Verifier) load(info os.FileInfo) error {
+	data, err := os.ReadFile(v.path)
+	if err == nil {
+		var keys []key
+		if keys, err = parseKeySet(data); err == nil {
+			v.mu.Lock()
+			v.keys = keys
+			v.mu.Unlock()
+			v.logger.Info("Loaded key set", "path", v.path, "keys", len(keys))
+		}
+	}
+
+	v.mu.Lock()
+	v.modTime, v.size = info.ModTime(), info.Size()
+	v.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("loading key set: %w", err)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringList is a claim that holds either a single string or an array of
+// strings, as aud may.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*l = stringList{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+// numericDate is a time given as seconds since the epoch.
+type numericDate float64
+
+// maxSeconds bounds numeric dates to what time.Time can represent;
+// fractions of a second are dropped.
+const maxSeconds = 1 << 62
+
+func (d numericDate) Time() time.Time {
+	return time.Unix(int64(max(min(float64(d), maxSeconds), -maxSeconds)), 0)
+}
@@ -0,0 +1,338 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+const audience = "banking-service"
+
+var now = time.Date(2025, 2, 12, 12, 0, 0, 0, time.UTC)
+
+func TestMain(m *testing.M) {
+	domain.GetTimeNow = func() time.Time { return now }
+
+	os.Exit(m.Run())
+}
+
+// signer signs tokens with a private key published under kid.
+type signer struct {
+	kid     string
+	alg     string
+	private crypto.Signer
+}
+
+func newSigner(t *testing.T, kid, alg string) signer {
+	t.Helper()
+
+	var private crypto.Signer
+	var err error
+	switch alg {
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "EdDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	}
+	assert.NilError(t, err)
+
+	return signer{kid: kid, alg: alg, private: private}
+}
+
+// jwk returns the public key of s as a JWK.
+func (s signer) jwk() map[string]string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch public := s.private.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": s.kid, "alg": s.alg, "use": "sig",
+			"n": b64(public.N.Bytes()), "e": b64(big.NewInt(int64(public.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		return map[string]string{"kty": "EC", "kid": s.kid, "crv": "P-256",
+			"x": b64(public.X.FillBytes(make([]byte, 32))), "y": b64(public.Y.FillBytes(make([]byte, 32)))}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": s.kid, "crv": "Ed25519", "x": b64(public)}
+	}
+	return nil
+}
+
+// sign returns a token holding claims, signed by s.
+func (s signer) sign(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		assert.NilError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(map[string]string{"alg": s.alg, "kid": s.kid, "typ": "JWT"}) + "." + segment(claims)
+
+	var signature []byte
+	var err error
+	switch private := s.private.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var r, ss *big.Int
+		r, ss, err = ecdsa.Sign(rand.Reader, private, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), ss.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(private, []byte(signed))
+	}
+	assert.NilError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeKeySet publishes the keys of signers at path, with a modification
+// time distinct from the previous version of the file.
+func writeKeySet(t *testing.T, path string, signers ...signer) {
+	t.Helper()
+
+	keys := make([]map[string]string, len(signers))
+	for i, s := range signers {
+		keys[i] = s.jwk()
+	}
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	assert.NilError(t, err)
+	assert.NilError(t, os.WriteFile(path, data, 0o600))
+	bump(t, path)
+}
+
+// bump moves the modification time of path forward, as a later write
+// would.
+func bump(t *testing.T, path string) {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	assert.NilError(t, err)
+	later := info.ModTime().Add(time.Second)
+	assert.NilError(t, os.Chtimes(path, later, later))
+}
+
+func newVerifier(t *testing.T, signers ...signer) (*Verifier, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeKeySet(t, path, signers...)
+	verifier, err := NewFileVerifier(path, audience, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.NilError(t, err)
+
+	return verifier, path
+}
+
+// validClaims returns the claims of a token that passes every check.
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":   "Alice",
+		"aud":   audience,
+		"exp":   now.Add(time.Hour).Unix(),
+		"nbf":   now.Add(-time.Minute).Unix(),
+		"roles": []string{"customer"},
+	}
+}
+
+func with(claims map[string]any, name string, value any) map[string]any {
+	claims[name] = value
+	return claims
+}
+
+func without(claims map[string]any, name string) map[string]any {
+	delete(claims, name)
+	return claims
+}
+
+func TestVerifier_Algorithms(t *testing.T) {
+	for _, alg := range []string{"RS256", "ES256", "EdDSA"} {
+		t.Run(alg, func(t *testing.T) {
+			// Given: A key set holding a key for the algorithm
+			s := newSigner(t, "key-1", alg)
+			verifier, _ := newVerifier(t, s)
+
+			// When: Authenticating a token signed with it
+			principal, err := verifier.Authenticate(context.Background(), s.sign(t, validClaims()))
+
+			// Then: The caller should be identified
+			assert.NilError(t, err)
+			assert.DeepEqual(t, principal, domain.Principal{Subject: "Alice", Role: domain.RoleCustomer})
+		})
+	}
+}
+
+func TestVerifier_MapsClaims(t *testing.T) {
+	s := newSigner(t, "key-1", "ES256")
+	verifier, _ := newVerifier(t, s)
+
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		expected domain.Principal
+	}{
+		{
+			name:     "Granted accounts",
+			claims:   with(validClaims(), "account_ids", []string{"acc-1", "acc-2"}),
+			expected: domain.Principal{Subject: "Alice", Role: domain.RoleCustomer, AccountIDs: []string{"acc-1", "acc-2"}},
+		},
+		{
+			name:     "Most privileged role",
+			claims:   with(validClaims(), "roles", []string{"auditor", "customer", "operator"}),
+			expected: domain.Principal{Subject: "Alice", Role: domain.RoleOperator},
+		},
+		{
+			name:     "Single role and audience",
+			claims:   with(with(validClaims(), "roles", "admin"), "aud", []string{"other", audience}),
+			expected: domain.Principal{Subject: "Alice", Role: domain.RoleAdmin},
+		},
+		{
+			name:     "Within leeway of expiry",
+			claims:   with(validClaims(), "exp", now.Add(-leeway/2).Unix()),
+			expected: domain.Principal{Subject: "Alice", Role: domain.RoleCustomer},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Authenticating a token with the claims
+			principal, err := verifier.Authenticate(context.Background(), s.sign(t, tc.claims))
+
+			// Then: They should be mapped to the principal
+			assert.NilError(t, err)
+			assert.DeepEqual(t, principal, tc.expected)
+		})
+	}
+}
+
+func TestVerifier_RejectsInvalidTokens(t *testing.T) {
+	s := newSigner(t, "key-1", "ES256")
+	verifier, _ := newVerifier(t, s)
+	unknown := newSigner(t, "key-2", "ES256")
+	impostor := newSigner(t, "key-1", "ES256")
+
+	valid := s.sign(t, validClaims())
+	tampered := valid[:len(valid)-4] + "AAAA"
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "Malformed", token: "not-a-token"},
+		{name: "Tampered signature", token: tampered},
+		{name: "Unknown key", token: unknown.sign(t, validClaims())},
+		{name: "Other key under a known ID", token: impostor.sign(t, validClaims())},
+		{name: "Unsigned", token: unsigned(t, validClaims())},
+		{name: "Expired", token: s.sign(t, with(validClaims(), "exp", now.Add(-time.Hour).Unix()))},
+		{name: "Without expiry", token: s.sign(t, without(validClaims(), "exp"))},
+		{name: "Not valid yet", token: s.sign(t, with(validClaims(), "nbf", now.Add(time.Hour).Unix()))},
+		{name: "Other audience", token: s.sign(t, with(validClaims(), "aud", "other"))},
+		{name: "Without audience", token: s.sign(t, without(validClaims(), "aud"))},
+		{name: "Without subject", token: s.sign(t, without(validClaims(), "sub"))},
+		{name: "Without known role", token: s.sign(t, with(validClaims(), "roles", []string{"auditor"}))},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Authenticating the token
+			_, err := verifier.Authenticate(context.Background(), tc.token)
+
+			// Then: It should be rejected
+			assert.Assert(t, errors.Is(err, domain.ErrUnauthenticated), "got %v", err)
+		})
+	}
+}
+
+// unsigned returns a token holding claims with the "none" algorithm.
+func unsigned(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "none"})
+	payload, err := json.Marshal(claims)
+	assert.NilError(t, err)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+func TestVerifier_ReloadsRotatedKeys(t *testing.T) {
+	ctx := context.Background()
+
+	// Given: A verifier for a key set with one key
+	old := newSigner(t, "old", "RS256")
+	verifier, path := newVerifier(t, old)
+
+	// When: The key set is rotated to a new key
+	rotated := newSigner(t, "new", "EdDSA")
+	writeKeySet(t, path, rotated)
+
+	// Then: Tokens signed with the new key should be accepted
+	_, err := verifier.Authenticate(ctx, rotated.sign(t, validClaims()))
+	assert.NilError(t, err)
+
+	// And: Tokens signed with the old key should be rejected
+	_, err = verifier.Authenticate(ctx, old.sign(t, validClaims()))
+	assert.Assert(t, errors.Is(err, domain.ErrUnauthenticated), "got %v", err)
+
+	// When: The key set is replaced by a broken file
+	assert.NilError(t, os.WriteFile(path, []byte(`{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAB"}]}`), 0o600))
+	bump(t, path)
+
+	// Then: The keys loaded before should stay in effect
+	_, err = verifier.Authenticate(ctx, rotated.sign(t, validClaims()))
+	assert.NilError(t, err)
+}
+
+func TestNewFileVerifier_Errors(t *testing.T) {
+	dir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name     string
+		contents string
+		audience string
+	}{
+		{name: "Missing audience", contents: `{"keys": []}`, audience: ""},
+		{name: "Not JSON", contents: `keys`, audience: audience},
+		{name: "No signing keys", contents: `{"keys": [{"kty": "RSA", "use": "enc"}]}`, audience: audience},
+		{name: "Unsupported key type", contents: `{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`, audience: audience},
+		{name: "Short RSA modulus", contents: `{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAB"}]}`, audience: audience},
+		{name: "Point not on the curve", contents: `{"keys": [{"kty": "EC", "crv": "P-256",
+			"x": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", "y": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAE"}]}`, audience: audience},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A key set file
+			path := filepath.Join(dir, "jwks.json")
+			assert.NilError(t, os.WriteFile(path, []byte(tc.contents), 0o600))
+
+			// When: Creating a verifier for it
+			_, err := NewFileVerifier(path, tc.audience, logger)
+
+			// Then: It should fail
+			assert.Assert(t, err != nil)
+		})
+	}
+
+	// And: A missing file should fail too
+	_, err := NewFileVerifier(filepath.Join(dir, "missing.json"), audience, logger)
+	assert.Assert(t, err != nil)
+}
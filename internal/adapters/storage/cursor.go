@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// transactionCursor is the sort key of the last transaction on a page:
+// its timestamp in Unix nanoseconds and its insertion sequence number,
+// which breaks ties between equal timestamps.
+type transactionCursor struct {
+	timestamp int64
+	seq       int64
+}
+
+func (c transactionCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%d", c.timestamp, c.seq))
+}
+
+// parseTransactionCursor decodes a cursor produced by String. The empty
+// string is the position before the first transaction.
+func parseTransactionCursor(s string) (c transactionCursor, ok bool, err error) {
+	if s == "" {
+		return transactionCursor{}, false, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return transactionCursor{}, false, domain.ErrInvalidCursor
+	}
+	if _, err := fmt.Sscanf(string(data), "%d.%d", &c.timestamp, &c.seq); err != nil {
+		return transactionCursor{}, false, domain.ErrInvalidCursor
+	}
+
+	return c, true, nil
+}
+
+// after reports whether the transaction with the given sort key comes
+// after the cursor.
+func (c transactionCursor) after(timestamp, seq int64) bool {
+	return timestamp > c.timestamp || (timestamp == c.timestamp && seq > c.seq)
+}
+
+// accountCursor is the ID of the last account on a page.
+type accountCursor string
+
+func (c accountCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c))
+}
+
+// parseAccountCursor decodes a cursor produced by String. The empty string
+// is the position before the first account.
+func parseAccountCursor(s string) (c accountCursor, ok bool, err error) {
+	if s == "" {
+		return "", false, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(data) == 0 {
+		return "", false, domain.ErrInvalidCursor
+	}
+
+	return accountCursor(data), true, nil
+}
@@ -0,0 +1,302 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+const (
+	walFileName      = "wal.log"
+	snapshotFileName = "snapshot.json"
+
+	// DefaultSnapshotInterval is the number of committed log records after
+	// which the state is snapshotted and the log truncated.
+	DefaultSnapshotInterval = 1000
+)
+
+// ErrRepositoryClosed is returned when committing to a closed FileRepository.
+var ErrRepositoryClosed = errors.New("repository is closed")
+
+// FileRepository is a durable implementation of Repository. Every commit is
+// appended to a write-ahead log and fsync'd before it is applied to the
+// in-memory state, which serves all reads. Every snapshotInterval commits
+// the state is written to a snapshot and the log is emptied. On open, the
+// snapshot is loaded and the log replayed on top of it.
+type FileRepository struct {
+	*MemoryRepository
+
+	dir              string
+	logger           *slog.Logger
+	wal              *wal
+	seq              uint64
+	sinceSnapshot    int
+	snapshotInterval int
+}
+
+// snapshot is the complete repository state as of log record Seq.
+type snapshot struct {
+	Seq          uint64                    `json:"seq"`
+	Accounts     []domain.Account          `json:"accounts"`
+	Transactions []domain.Transaction      `json:"transactions"`
+	Transfers    []domain.Transfer         `json:"transfers"`
+	Entries      []domain.LedgerEntry      `json:"entries"`
+	AuditEvents  []domain.AuditEvent       `json:"audit_events"`
+	APIKeys      []domain.APIKey           `json:"api_keys"`
+	Approvals    []domain.TransferApproval `json:"approvals"`
+}
+
+// NewFileRepository opens the repository stored in dir, creating the
+// directory if needed, and recovers its state from the snapshot and log.
+// snapshotInterval <= 0 selects DefaultSnapshotInterval.
+func NewFileRepository(dir string, snapshotInterval int, logger *slog.Logger) (*FileRepository, error) {
+	if snapshotInterval <= 0 {
+		snapshotInterval = DefaultSnapshotInterval
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+
+	r := &FileRepository{
+		MemoryRepository: newMemoryRepository(),
+		dir:              dir,
+		logger:           logger.With("component", "FileRepository"),
+		snapshotInterval: snapshotInterval,
+	}
+
+	if err := r.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	wal, records, torn, err := openWAL(filepath.Join(dir, walFileName))
+	if err != nil {
+		return nil, fmt.Errorf("opening log: %w", err)
+	}
+	if torn > 0 {
+		r.logger.Warn("Truncated incomplete record at end of log", "bytes", torn)
+	}
+
+	if err := r.replay(records); err != nil {
+		wal.close()
+		return nil, err
+	}
+	r.wal = wal
+
+	r.logger.Info("Recovered repository", "seq", r.seq, "replayed", r.sinceSnapshot)
+	return r, nil
+}
+
+func (r *FileRepository) CreateAccount(ctx context.Context, account domain.Account) error {
+	return r.Commit(ctx, ports.Changeset{NewAccounts: []domain.Account{account}})
+}
+
+func (r *FileRepository) Record(ctx context.Context, account domain.Account, txn domain.Transaction) error {
+	return r.Commit(ctx, ports.Changeset{
+		Accounts:     []domain.Account{account},
+		Transactions: []domain.Transaction{txn},
+	})
+}
+
+// Commit validates changes, makes them durable in the log and only then
+// applies them, so that a change is never visible before it would survive
+// a crash.
+func (r *FileRepository) Commit(ctx context.Context, changes ports.Changeset) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.wal == nil {
+		return ErrRepositoryClosed
+	}
+	if err := r.validate(changes); err != nil {
+		return err
+	}
+
+	if err := r.wal.append(newWALRecord(r.seq+1, changes)); err != nil {
+		return fmt.Errorf("writing log: %w", err)
+	}
+	r.seq++
+	r.apply(changes)
+
+	if r.sinceSnapshot++; r.sinceSnapshot >= r.snapshotInterval {
+		// The commit is already durable; a failed snapshot only means the
+		// log keeps growing until the next attempt
+		if err := r.writeSnapshot(); err != nil {
+			r.logger.ErrorContext(ctx, "Failed to write snapshot", "error", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Close closes the log. Reads keep working, but further commits fail with
+// ErrRepositoryClosed.
+func (r *FileRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.wal == nil {
+		return nil
+	}
+
+	err := r.wal.close()
+	r.wal = nil
+	return err
+}
+
+// loadSnapshot restores the state saved by writeSnapshot, if there is one.
+func (r *FileRepository) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("decoding snapshot: %w", err)
+	}
+
+	r.seq = snap.Seq
+	for _, account := range snap.Accounts {
+		r.insertAccount(account)
+	}
+	for _, txn := range snap.Transactions {
+		r.insertTransaction(txn)
+	}
+	for _, transfer := range snap.Transfers {
+		r.transfers[transfer.ID] = transfer
+	}
+	r.entries = snap.Entries
+	for _, event := range snap.AuditEvents {
+		r.auditEvents[event.AccountID] = append(r.auditEvents[event.AccountID], event)
+	}
+	for _, key := range snap.APIKeys {
+		r.apiKeys[key.Hash] = key
+	}
+	for _, approval := range snap.Approvals {
+		r.approvals[approval.ID] = approval
+	}
+
+	return nil
+}
+
+// replay applies the log records that are not yet part of the snapshot.
+// Records up to the snapshot's sequence number are left over from a crash
+// between writing the snapshot and emptying the log, and are skipped.
+func (r *FileRepository) replay(records []walRecord) error {
+	for _, record := range records {
+		if record.Seq <= r.seq {
+			continue
+		}
+		if record.Seq != r.seq+1 {
+			return fmt.Errorf("log record %d follows record %d", record.Seq, r.seq)
+		}
+
+		changes := record.changeset()
+		if err := r.validate(changes); err != nil {
+			return fmt.Errorf("replaying log record %d: %w", record.Seq, err)
+		}
+		r.apply(changes)
+
+		r.seq = record.Seq
+		r.sinceSnapshot++
+	}
+
+	return nil
+}
+
+// writeSnapshot atomically replaces the snapshot with the current state and
+// empties the log. The caller must hold r.mu.
+func (r *FileRepository) writeSnapshot() error {
+	snap := snapshot{
+		Seq:      r.seq,
+		Accounts: make([]domain.Account, 0, len(r.accounts)),
+		Entries:  r.entries,
+	}
+	for _, account := range r.accounts {
+		snap.Accounts = append(snap.Accounts, account)
+	}
+	for _, transactions := range r.transactions {
+		for _, txn := range transactions {
+			snap.Transactions = append(snap.Transactions, txn.Transaction)
+		}
+	}
+	for _, transfer := range r.transfers {
+		snap.Transfers = append(snap.Transfers, transfer)
+	}
+	for _, events := range r.auditEvents {
+		snap.AuditEvents = append(snap.AuditEvents, events...)
+	}
+	for _, key := range r.apiKeys {
+		snap.APIKeys = append(snap.APIKeys, key)
+	}
+	for _, approval := range r.approvals {
+		snap.Approvals = append(snap.Approvals, approval)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(r.dir, snapshotFileName)
+	if err := writeFileSync(path+".tmp", data); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	if err := syncDir(r.dir); err != nil {
+		return err
+	}
+
+	// Only now is it safe to drop the records the snapshot covers
+	if err := r.wal.reset(); err != nil {
+		return err
+	}
+	r.sinceSnapshot = 0
+
+	return nil
+}
+
+// writeFileSync writes data to path and syncs it to disk.
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// syncDir makes a rename within dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
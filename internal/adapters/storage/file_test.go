@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/storage/storagetest"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+func TestFileRepository_Contract(t *testing.T) {
+	storagetest.RunRepositoryContract(t, func(t *testing.T) ports.Repository {
+		return openFileRepository(t, t.TempDir(), 0)
+	})
+}
+
+func openFileRepository(t *testing.T, dir string, snapshotInterval int) *FileRepository {
+	t.Helper()
+
+	repo, err := NewFileRepository(dir, snapshotInterval, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+
+	return repo
+}
+
+// commitTransfers creates two accounts and commits n transfers between
+// them, returning the accounts as stored.
+func commitTransfers(t *testing.T, repo ports.Repository, n int) (domain.Account, domain.Account) {
+	t.Helper()
+	ctx := context.Background()
+
+	from, _ := domain.NewAccount(domain.GetUUID(), "foo", "USD", domain.MustParseMoney("100"))
+	to, _ := domain.NewAccount(domain.GetUUID(), "bar", "USD", domain.MustParseMoney("0"))
+	assert.NilError(t, repo.CreateAccount(ctx, from))
+	assert.NilError(t, repo.CreateAccount(ctx, to))
+
+	for range n {
+		from, _ = repo.GetAccount(ctx, from.ID)
+		to, _ = repo.GetAccount(ctx, to.ID)
+
+		transfer, err := from.Transfer(&to, domain.MustParseMoney("1"), "USD")
+		assert.NilError(t, err)
+
+		assert.NilError(t, repo.Commit(ctx, ports.Changeset{
+			Accounts:     []domain.Account{from, to},
+			Transactions: []domain.Transaction{transfer.Withdrawal, transfer.Deposit},
+			Transfers:    []domain.Transfer{transfer},
+			Entries: append(
+				domain.PostTransaction(transfer.Withdrawal, domain.LedgerSuspense),
+				domain.PostTransaction(transfer.Deposit, domain.LedgerSuspense)...,
+			),
+		}))
+	}
+
+	from, _ = repo.GetAccount(ctx, from.ID)
+	to, _ = repo.GetAccount(ctx, to.ID)
+	return from, to
+}
+
+// assertSameState checks that two repositories hold the same data.
+func assertSameState(t *testing.T, expected, actual ports.Repository, accountIDs ...string) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, id := range accountIDs {
+		expectedAccount, err := expected.GetAccount(ctx, id)
+		assert.NilError(t, err)
+		actualAccount, err := actual.GetAccount(ctx, id)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actualAccount, expectedAccount)
+
+		expectedTxns, err := expected.ListTransactions(ctx, id)
+		assert.NilError(t, err)
+		actualTxns, err := actual.ListTransactions(ctx, id)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actualTxns, expectedTxns)
+	}
+
+	expectedLedger, err := expected.LedgerSnapshot(ctx)
+	assert.NilError(t, err)
+	actualLedger, err := actual.LedgerSnapshot(ctx)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, actualLedger.Entries, expectedLedger.Entries)
+}
+
+func TestFileRepository_RecoversStateOnReopen(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// Given: A repository with accounts, transfers and ledger entries
+	repo := openFileRepository(t, dir, 0)
+	from, to := commitTransfers(t, repo, 3)
+	assert.NilError(t, repo.Close())
+
+	// When: The repository is reopened
+	reopened := openFileRepository(t, dir, 0)
+
+	// Then: All state should be recovered
+	assertSameState(t, repo, reopened, from.ID, to.ID)
+	assert.Equal(t, from.Version, int64(3))
+
+	// And: Transfers should be retrievable
+	txns, err := reopened.ListTransactions(ctx, from.ID)
+	assert.NilError(t, err)
+	transfer, err := reopened.GetTransfer(ctx, txns[0].TransferID)
+	assert.NilError(t, err)
+	assert.Equal(t, transfer.ToAccountID(), to.ID)
+
+	// And: New commits should build on the recovered versions
+	_, _ = commitTransfers(t, reopened, 1)
+	txn, _ := from.Withdraw(domain.MustParseMoney("1"), "USD")
+	assert.NilError(t, reopened.Record(ctx, from, txn))
+}
+
+func TestFileRepository_RecoversFromSnapshotAndLog(t *testing.T) {
+	dir := t.TempDir()
+
+	// Given: More commits than the snapshot interval
+	repo := openFileRepository(t, dir, 4)
+	from, to := commitTransfers(t, repo, 5)
+	assert.NilError(t, repo.Close())
+
+	// Then: A snapshot should have been written and the log truncated
+	_, err := os.Stat(filepath.Join(dir, snapshotFileName))
+	assert.NilError(t, err)
+	assert.Equal(t, repo.seq, uint64(7))
+	assert.Equal(t, repo.sinceSnapshot, 3)
+
+	// When: The repository is reopened
+	reopened := openFileRepository(t, dir, 4)
+
+	// Then: The snapshot and the remaining log records should be combined
+	assertSameState(t, repo, reopened, from.ID, to.ID)
+	assert.Equal(t, reopened.seq, uint64(7))
+}
+
+func TestFileRepository_SkipsLogRecordsCoveredBySnapshot(t *testing.T) {
+	dir := t.TempDir()
+	walPath := filepath.Join(dir, walFileName)
+
+	// Given: A snapshot whose log was not emptied, as after a crash between
+	// writing the snapshot and truncating the log
+	repo := openFileRepository(t, dir, 4)
+	from, to := commitTransfers(t, repo, 1)
+	stale, err := os.ReadFile(walPath)
+	assert.NilError(t, err)
+
+	from, _ = repo.GetAccount(context.Background(), from.ID)
+	txn, _ := from.Withdraw(domain.MustParseMoney("1"), "USD")
+	assert.NilError(t, repo.Record(context.Background(), from, txn))
+	assert.NilError(t, repo.Close())
+	assert.NilError(t, os.WriteFile(walPath, stale, 0o600))
+
+	// When: The repository is reopened
+	reopened := openFileRepository(t, dir, 4)
+
+	// Then: The stale records should not be applied twice
+	assertSameState(t, repo, reopened, from.ID, to.ID)
+}
+
+func TestFileRepository_TruncatesTornRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+	}{
+		{
+			name:    "Partial header",
+			corrupt: func(data []byte) []byte { return append(data, 0x2a, 0x00, 0x00) },
+		},
+		{
+			name: "Partial payload",
+			corrupt: func(data []byte) []byte {
+				return append(data, 0xff, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03, 0x04, '{', '"')
+			},
+		},
+		{
+			name: "Checksum mismatch",
+			corrupt: func(data []byte) []byte {
+				data[len(data)-2] ^= 0xff
+				return data
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			walPath := filepath.Join(dir, walFileName)
+			ctx := context.Background()
+
+			// Given: A log whose final record was torn by a crash
+			repo := openFileRepository(t, dir, 0)
+			from, _ := commitTransfers(t, repo, 1)
+			assert.NilError(t, repo.Close())
+
+			data, err := os.ReadFile(walPath)
+			assert.NilError(t, err)
+			assert.NilError(t, os.WriteFile(walPath, tc.corrupt(data), 0o600))
+
+			// When: The repository is reopened
+			reopened := openFileRepository(t, dir, 0)
+
+			// Then: Every complete record before the torn one is recovered
+			_, err = reopened.GetAccount(ctx, from.ID)
+			assert.NilError(t, err)
+
+			// And: New commits are appended after the last good record
+			from, _ = reopened.GetAccount(ctx, from.ID)
+			txn, _ := from.Deposit(domain.MustParseMoney("5"), "USD")
+			assert.NilError(t, reopened.Record(ctx, from, txn))
+			assert.NilError(t, reopened.Close())
+
+			recovered := openFileRepository(t, dir, 0)
+			stored, err := recovered.GetAccount(ctx, from.ID)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, stored.Balance, from.Balance)
+		})
+	}
+}
+
+func TestFileRepository_RefusesCorruptRecordBeforeEnd(t *testing.T) {
+	dir := t.TempDir()
+	walPath := filepath.Join(dir, walFileName)
+
+	// Given: A log whose first record fails its checksum, with committed
+	// records after it
+	repo := openFileRepository(t, dir, 0)
+	commitTransfers(t, repo, 2)
+	assert.NilError(t, repo.Close())
+
+	data, err := os.ReadFile(walPath)
+	assert.NilError(t, err)
+	data[walHeaderSize+2] ^= 0xff
+	assert.NilError(t, os.WriteFile(walPath, data, 0o600))
+
+	// When: The repository is reopened
+	_, err = NewFileRepository(dir, 0, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Then: It should fail rather than drop the later records
+	assert.Assert(t, errors.Is(err, errCorruptFrame), "got %v", err)
+
+	after, err := os.ReadFile(walPath)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, after, data)
+}
+
+func TestFileRepository_CommitAfterClose(t *testing.T) {
+	repo := openFileRepository(t, t.TempDir(), 0)
+	ctx := context.Background()
+
+	// Given: A closed repository
+	assert.NilError(t, repo.Close())
+
+	// When: Committing a change
+	account, _ := domain.NewAccount(domain.GetUUID(), "foo", "USD", domain.MustParseMoney("100"))
+	err := repo.CreateAccount(ctx, account)
+
+	// Then: It should be rejected
+	assert.Assert(t, errors.Is(err, ErrRepositoryClosed))
+}
+
+func TestFileRepository_RecoversKeysAndApprovals(t *testing.T) {
+	for _, snapshotInterval := range []int{0, 1} {
+		t.Run(fmt.Sprintf("Snapshot interval %d", snapshotInterval), func(t *testing.T) {
+			dir := t.TempDir()
+			ctx := context.Background()
+
+			// Given: A repository holding an API key and a decided approval
+			repo := openFileRepository(t, dir, snapshotInterval)
+			key, err := domain.NewAPIKey("token", "Alice", domain.RoleCustomer)
+			assert.NilError(t, err)
+			approval := domain.NewTransferApproval("Alice", "from", "to", domain.MustParseMoney("5000"), "USD")
+			assert.NilError(t, repo.Commit(ctx, ports.Changeset{
+				APIKeys:      []domain.APIKey{key},
+				NewApprovals: []domain.TransferApproval{approval},
+			}))
+			assert.NilError(t, approval.Reject("Olivia", "unusual recipient"))
+			assert.NilError(t, repo.Commit(ctx, ports.Changeset{Approvals: []domain.TransferApproval{approval}}))
+			assert.NilError(t, repo.Close())
+
+			// When: The repository is reopened
+			reopened := openFileRepository(t, dir, snapshotInterval)
+
+			// Then: The key should still authenticate its token
+			actual, err := reopened.GetAPIKey(ctx, key.Hash)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, actual.Principal, key.Principal)
+
+			// And: The approval should keep its history
+			stored, err := reopened.GetTransferApproval(ctx, approval.ID)
+			assert.NilError(t, err)
+			approval.Version++
+			assert.DeepEqual(t, stored, approval)
+		})
+	}
+}
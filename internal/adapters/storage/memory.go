@@ -3,7 +3,9 @@ package storage
 import (
 	"context"
 	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/hesampakdaman/banking-service/internal/domain"
 	"github.com/hesampakdaman/banking-service/internal/ports"
@@ -11,31 +13,46 @@ import (
 
 // MemoryRepository provides an in-memory implementation of Repository.
 type MemoryRepository struct {
-	mu           sync.RWMutex
-	accounts     map[string]domain.Account
-	transactions map[string][]domain.Transaction
+	mu       sync.RWMutex
+	accounts map[string]domain.Account
+	// accountIDs holds the keys of accounts in ascending order.
+	accountIDs []string
+	// transactions holds each account's transactions ordered by timestamp,
+	// ties in insertion order.
+	transactions map[string][]storedTransaction
+	nextSeq      int64
+	transfers    map[string]domain.Transfer
+	entries      []domain.LedgerEntry
+	auditEvents  map[string][]domain.AuditEvent
+	// apiKeys maps the hash of each key to the key.
+	apiKeys   map[string]domain.APIKey
+	approvals map[string]domain.TransferApproval
 }
 
 func NewMemoryRepository() ports.Repository {
+	return newMemoryRepository()
+}
+
+func newMemoryRepository() *MemoryRepository {
 	return &MemoryRepository{
 		accounts:     make(map[string]domain.Account),
-		transactions: make(map[string][]domain.Transaction),
+		transactions: make(map[string][]storedTransaction),
+		transfers:    make(map[string]domain.Transfer),
+		auditEvents:  make(map[string][]domain.AuditEvent),
+		apiKeys:      make(map[string]domain.APIKey),
+		approvals:    make(map[string]domain.TransferApproval),
 	}
 }
 
 func (r *MemoryRepository) CreateAccount(ctx context.Context, account domain.Account) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if _, exists := r.accounts[account.ID]; exists {
-		return domain.ErrAccountAlreadyExists
-	}
-
-	r.accounts[account.ID] = account
-	return nil
+	return r.Commit(ctx, ports.Changeset{NewAccounts: []domain.Account{account}})
 }
 
 func (r *MemoryRepository) GetAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Account{}, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -47,57 +64,408 @@ func (r *MemoryRepository) GetAccount(ctx context.Context, accountID string) (do
 	return account, nil
 }
 
-func (r *MemoryRepository) ListAccounts(ctx context.Context) []domain.Account {
+func (r *MemoryRepository) ListAccounts(ctx context.Context) ([]domain.Account, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	accounts := make([]domain.Account, 0, len(r.accounts))
-	for _, account := range r.accounts {
-		accounts = append(accounts, account)
+	for _, id := range r.accountIDs {
+		accounts = append(accounts, r.accounts[id])
 	}
 
-	return accounts
+	return accounts, nil
+}
+
+// QueryAccounts walks the accounts in ID order. Every account is matched
+// against the filters to compute the total, so the cost is linear in the
+// number of accounts.
+func (r *MemoryRepository) QueryAccounts(ctx context.Context, query ports.AccountQuery) (ports.AccountPage, error) {
+	if err := ctx.Err(); err != nil {
+		return ports.AccountPage{}, err
+	}
+	cursor, hasCursor, err := parseAccountCursor(query.Cursor)
+	if err != nil {
+		return ports.AccountPage{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := ports.AccountPage{Accounts: []domain.Account{}}
+	for _, id := range r.accountIDs {
+		account := r.accounts[id]
+		if !query.Matches(account) {
+			continue
+		}
+		page.Total++
+
+		if hasCursor && id <= string(cursor) {
+			continue
+		}
+		switch {
+		case len(page.Accounts) < query.Limit:
+			page.Accounts = append(page.Accounts, account)
+		case page.NextCursor == "":
+			page.NextCursor = accountCursor(page.Accounts[len(page.Accounts)-1].ID).String()
+		}
+	}
+
+	return page, nil
 }
 
 func (r *MemoryRepository) Record(ctx context.Context, account domain.Account, txn domain.Transaction) error {
+	return r.Commit(ctx, ports.Changeset{
+		Accounts:     []domain.Account{account},
+		Transactions: []domain.Transaction{txn},
+	})
+}
+
+func (r *MemoryRepository) Commit(ctx context.Context, changes ports.Changeset) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	_, exists := r.accounts[account.ID]
-	if !exists {
-		return domain.ErrInvalidAccountID
+	// Validate the whole changeset before touching any state
+	if err := r.validate(changes); err != nil {
+		return err
 	}
 
-	if account.ID != txn.AccountID {
-		return domain.ErrAccountTransactionMismatch
+	r.apply(changes)
+	return nil
+}
+
+// validate checks that changes can be applied to the current state.
+// The caller must hold r.mu.
+func (r *MemoryRepository) validate(changes ports.Changeset) error {
+	updated := make(map[string]bool, len(changes.NewAccounts)+len(changes.Accounts))
+	for _, account := range changes.NewAccounts {
+		if _, exists := r.accounts[account.ID]; exists || updated[account.ID] {
+			return domain.ErrAccountAlreadyExists
+		}
+		updated[account.ID] = true
 	}
 
-	r.accounts[account.ID] = account
-	r.transactions[txn.AccountID] = append(r.transactions[txn.AccountID], txn)
+	for _, account := range changes.Accounts {
+		stored, exists := r.accounts[account.ID]
+		if !exists {
+			return domain.ErrInvalidAccountID
+		}
+		if stored.Version != account.Version || updated[account.ID] {
+			return domain.ErrVersionConflict
+		}
+		updated[account.ID] = true
+	}
+
+	inChangeset := make(map[string]bool, len(changes.Transactions))
+	for _, txn := range changes.Transactions {
+		if !updated[txn.AccountID] {
+			return domain.ErrAccountTransactionMismatch
+		}
+		inChangeset[txn.ID] = true
+	}
+
+	for _, transfer := range changes.Transfers {
+		if !inChangeset[transfer.Withdrawal.ID] || !inChangeset[transfer.Deposit.ID] {
+			return domain.ErrAccountTransactionMismatch
+		}
+	}
+
+	for _, event := range changes.AuditEvents {
+		if !updated[event.AccountID] {
+			return domain.ErrAccountTransactionMismatch
+		}
+	}
+
+	newKeys := make(map[string]bool, len(changes.APIKeys))
+	for _, key := range changes.APIKeys {
+		if _, exists := r.apiKeys[key.Hash]; exists || newKeys[key.Hash] {
+			return domain.ErrAPIKeyAlreadyExists
+		}
+		newKeys[key.Hash] = true
+	}
+
+	decided := make(map[string]bool, len(changes.NewApprovals)+len(changes.Approvals))
+	for _, approval := range changes.NewApprovals {
+		if _, exists := r.approvals[approval.ID]; exists || decided[approval.ID] {
+			return domain.ErrApprovalAlreadyExists
+		}
+		decided[approval.ID] = true
+	}
+	for _, approval := range changes.Approvals {
+		stored, exists := r.approvals[approval.ID]
+		if !exists {
+			return domain.ErrApprovalNotFound
+		}
+		if stored.Version != approval.Version || decided[approval.ID] {
+			return domain.ErrVersionConflict
+		}
+		decided[approval.ID] = true
+	}
 
 	return nil
 }
 
-func (r *MemoryRepository) ListTransactions(ctx context.Context, accountID string) []domain.Transaction {
+// apply stores a validated changeset. The caller must hold r.mu.
+func (r *MemoryRepository) apply(changes ports.Changeset) {
+	for _, account := range changes.NewAccounts {
+		r.insertAccount(account)
+	}
+	for _, account := range changes.Accounts {
+		account.Version++
+		r.accounts[account.ID] = account
+	}
+	for _, txn := range changes.Transactions {
+		r.insertTransaction(txn)
+	}
+	for _, transfer := range changes.Transfers {
+		r.transfers[transfer.ID] = transfer
+	}
+	r.entries = append(r.entries, changes.Entries...)
+	for _, event := range changes.AuditEvents {
+		r.auditEvents[event.AccountID] = append(r.auditEvents[event.AccountID], event)
+	}
+	for _, key := range changes.APIKeys {
+		r.apiKeys[key.Hash] = key
+	}
+	for _, approval := range changes.NewApprovals {
+		r.approvals[approval.ID] = cloneApproval(approval)
+	}
+	for _, approval := range changes.Approvals {
+		approval.Version++
+		r.approvals[approval.ID] = cloneApproval(approval)
+	}
+}
+
+func (r *MemoryRepository) ListTransactions(ctx context.Context, accountID string) ([]domain.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	transactions, exists := r.transactions[accountID]
-	if !exists {
-		return []domain.Transaction{}
+	if _, exists := r.accounts[accountID]; !exists {
+		return nil, domain.ErrInvalidAccountID
 	}
 
-	sortedTransactions := make([]domain.Transaction, len(transactions))
-	copy(sortedTransactions, transactions)
-	slices.SortFunc(sortedTransactions, func(a, b domain.Transaction) int {
-		switch {
-		case a.Timestamp.Before(b.Timestamp):
+	stored := r.transactions[accountID]
+	transactions := make([]domain.Transaction, len(stored))
+	for i, txn := range stored {
+		transactions[i] = txn.Transaction
+	}
+
+	return transactions, nil
+}
+
+func (r *MemoryRepository) QueryTransactions(ctx context.Context, query ports.TransactionQuery) (ports.TransactionPage, error) {
+	if err := ctx.Err(); err != nil {
+		return ports.TransactionPage{}, err
+	}
+	cursor, hasCursor, err := parseTransactionCursor(query.Cursor)
+	if err != nil {
+		return ports.TransactionPage{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.accounts[query.AccountID]; !exists {
+		return ports.TransactionPage{}, domain.ErrInvalidAccountID
+	}
+
+	// Skip straight to the first candidate: after the cursor and not
+	// before the From bound
+	stored := r.transactions[query.AccountID]
+	start := 0
+	if hasCursor {
+		start, _ = slices.BinarySearchFunc(stored, cursor, func(txn storedTransaction, c transactionCursor) int {
+			if c.after(txn.Timestamp.UnixNano(), txn.seq) {
+				return 1
+			}
 			return -1
-		case a.Timestamp.After(b.Timestamp):
-			return 1
-		default:
-			return 0
+		})
+	}
+	if !query.From.IsZero() {
+		from, _ := slices.BinarySearchFunc(stored, query.From, func(txn storedTransaction, from time.Time) int {
+			return txn.Timestamp.Compare(from)
+		})
+		start = max(start, from)
+	}
+
+	page := ports.TransactionPage{Transactions: []domain.Transaction{}}
+	var last storedTransaction
+	for _, txn := range stored[start:] {
+		if !query.To.IsZero() && !txn.Timestamp.Before(query.To) {
+			break
+		}
+		if !query.Matches(txn.Transaction) {
+			continue
+		}
+		if len(page.Transactions) == query.Limit {
+			page.NextCursor = last.cursor().String()
+			break
+		}
+		page.Transactions = append(page.Transactions, txn.Transaction)
+		last = txn
+	}
+
+	return page, nil
+}
+
+func (r *MemoryRepository) GetTransfer(ctx context.Context, transferID string) (domain.Transfer, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Transfer{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transfer, exists := r.transfers[transferID]
+	if !exists {
+		return domain.Transfer{}, domain.ErrTransferNotFound
+	}
+
+	return transfer, nil
+}
+
+func (r *MemoryRepository) LedgerSnapshot(ctx context.Context) (ports.LedgerSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return ports.LedgerSnapshot{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := make([]domain.Account, 0, len(r.accounts))
+	for _, account := range r.accounts {
+		accounts = append(accounts, account)
+	}
+
+	return ports.LedgerSnapshot{
+		Accounts: accounts,
+		Entries:  slices.Clone(r.entries),
+	}, nil
+}
+
+func (r *MemoryRepository) ListAuditEvents(ctx context.Context, accountID string) ([]domain.AuditEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, exists := r.accounts[accountID]; !exists {
+		return nil, domain.ErrInvalidAccountID
+	}
+
+	return append([]domain.AuditEvent{}, r.auditEvents[accountID]...), nil
+}
+
+func (r *MemoryRepository) GetAPIKey(ctx context.Context, hash string) (domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.apiKeys[hash]
+	if !exists {
+		return domain.APIKey{}, domain.ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+func (r *MemoryRepository) GetTransferApproval(ctx context.Context, approvalID string) (domain.TransferApproval, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.TransferApproval{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	approval, exists := r.approvals[approvalID]
+	if !exists {
+		return domain.TransferApproval{}, domain.ErrApprovalNotFound
+	}
+
+	return cloneApproval(approval), nil
+}
+
+func (r *MemoryRepository) ListTransferApprovals(ctx context.Context, status domain.ApprovalStatus) ([]domain.TransferApproval, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	approvals := []domain.TransferApproval{}
+	for _, approval := range r.approvals {
+		if status == "" || approval.Status == status {
+			approvals = append(approvals, cloneApproval(approval))
 		}
+	}
+	sortApprovals(approvals)
+
+	return approvals, nil
+}
+
+// cloneApproval copies approval's history, so that appending to it does
+// not change the stored approval.
+func cloneApproval(approval domain.TransferApproval) domain.TransferApproval {
+	approval.History = slices.Clone(approval.History)
+	return approval
+}
+
+// sortApprovals orders approvals by request time, ties by ID.
+func sortApprovals(approvals []domain.TransferApproval) {
+	slices.SortFunc(approvals, func(a, b domain.TransferApproval) int {
+		if c := a.RequestedAt.Compare(b.RequestedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
 	})
-	return sortedTransactions
+}
+
+// insertAccount adds a new account, keeping accountIDs ordered. The caller
+// must hold r.mu.
+func (r *MemoryRepository) insertAccount(account domain.Account) {
+	i, _ := slices.BinarySearch(r.accountIDs, account.ID)
+	r.accountIDs = slices.Insert(r.accountIDs, i, account.ID)
+	r.accounts[account.ID] = account
+}
+
+// storedTransaction is a transaction with the sequence number that orders
+// it among transactions with the same timestamp.
+type storedTransaction struct {
+	domain.Transaction
+	seq int64
+}
+
+func (t storedTransaction) cursor() transactionCursor {
+	return transactionCursor{timestamp: t.Timestamp.UnixNano(), seq: t.seq}
+}
+
+// insertTransaction adds txn to its account's transactions, keeping them
+// ordered. Transactions usually arrive in timestamp order, so this is
+// normally an append. The caller must hold r.mu.
+func (r *MemoryRepository) insertTransaction(txn domain.Transaction) {
+	r.nextSeq++
+	stored := r.transactions[txn.AccountID]
+
+	i := len(stored)
+	for i > 0 && stored[i-1].Timestamp.After(txn.Timestamp) {
+		i--
+	}
+	r.transactions[txn.AccountID] = slices.Insert(stored, i, storedTransaction{Transaction: txn, seq: r.nextSeq})
 }
@@ -0,0 +1,57 @@
+-- Amounts are stored as decimal strings so that they round-trip exactly,
+-- and timestamps as Unix nanoseconds so that they sort correctly.
+
+CREATE TABLE accounts (
+    id              TEXT PRIMARY KEY,
+    owner           TEXT NOT NULL,
+    currency        TEXT NOT NULL,
+    balance         TEXT NOT NULL,
+    overdraft_limit TEXT NOT NULL,
+    status          TEXT NOT NULL,
+    version         INTEGER NOT NULL
+);
+
+CREATE TABLE transactions (
+    seq                     INTEGER PRIMARY KEY AUTOINCREMENT,
+    id                      TEXT NOT NULL UNIQUE,
+    account_id              TEXT NOT NULL REFERENCES accounts (id),
+    type                    TEXT NOT NULL,
+    amount                  TEXT NOT NULL,
+    currency                TEXT NOT NULL,
+    conversion              TEXT,
+    transfer_id             TEXT,
+    counterparty_account_id TEXT,
+    timestamp               INTEGER NOT NULL
+);
+
+CREATE INDEX transactions_account_id_timestamp ON transactions (account_id, timestamp);
+
+CREATE TABLE transfers (
+    id            TEXT PRIMARY KEY,
+    withdrawal_id TEXT NOT NULL REFERENCES transactions (id),
+    deposit_id    TEXT NOT NULL REFERENCES transactions (id),
+    timestamp     INTEGER NOT NULL
+);
+
+CREATE TABLE ledger_entries (
+    seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+    id        TEXT NOT NULL UNIQUE,
+    reference TEXT NOT NULL,
+    account   TEXT NOT NULL,
+    direction TEXT NOT NULL,
+    amount    TEXT NOT NULL,
+    currency  TEXT NOT NULL,
+    timestamp INTEGER NOT NULL
+);
+
+CREATE TABLE audit_events (
+    seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+    id         TEXT NOT NULL UNIQUE,
+    account_id TEXT NOT NULL REFERENCES accounts (id),
+    action     TEXT NOT NULL,
+    old_value  TEXT NOT NULL,
+    new_value  TEXT NOT NULL,
+    timestamp  INTEGER NOT NULL
+);
+
+CREATE INDEX audit_events_account_id ON audit_events (account_id);
@@ -0,0 +1,10 @@
+-- Only a hash of each API key is stored; the key itself is shown once,
+-- when it is issued.
+
+CREATE TABLE api_keys (
+    id         TEXT PRIMARY KEY,
+    hash       TEXT NOT NULL UNIQUE,
+    subject    TEXT NOT NULL,
+    role       TEXT NOT NULL,
+    created_at INTEGER NOT NULL
+);
@@ -0,0 +1,27 @@
+-- Transfers awaiting approval, and those that were decided on. Their
+-- history is append-only.
+
+CREATE TABLE transfer_approvals (
+    id              TEXT PRIMARY KEY,
+    from_account_id TEXT NOT NULL,
+    to_account_id   TEXT NOT NULL,
+    amount          TEXT NOT NULL,
+    currency        TEXT NOT NULL,
+    status          TEXT NOT NULL,
+    requested_by    TEXT NOT NULL,
+    requested_at    INTEGER NOT NULL,
+    transfer_id     TEXT REFERENCES transfers (id),
+    version         INTEGER NOT NULL
+);
+
+CREATE INDEX transfer_approvals_status ON transfer_approvals (status, requested_at);
+
+CREATE TABLE approval_events (
+    approval_id TEXT NOT NULL REFERENCES transfer_approvals (id),
+    seq         INTEGER NOT NULL,
+    action      TEXT NOT NULL,
+    actor       TEXT NOT NULL,
+    reason      TEXT NOT NULL,
+    timestamp   INTEGER NOT NULL,
+    PRIMARY KEY (approval_id, seq)
+);
@@ -0,0 +1,843 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+
+	// Registers the cgo-free "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// SQLiteRepository is an implementation of Repository backed by an embedded
+// SQLite database. Every Commit runs in a single database transaction.
+type SQLiteRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewSQLiteRepository opens (or creates) the SQLite database at path and
+// applies any pending schema migrations.
+func NewSQLiteRepository(path string, logger *slog.Logger) (*SQLiteRepository, error) {
+	// Write transactions take the database lock up front, so concurrent
+	// writers wait for each other (up to the busy timeout) instead of
+	// failing when upgrading a read lock. Read-only transactions are still
+	// deferred, so readers never contend with writers.
+	dsn := url.URL{
+		Scheme: "file",
+		Path:   path,
+		RawQuery: url.Values{
+			"_pragma": {"busy_timeout(5000)", "journal_mode(WAL)", "foreign_keys(1)", "synchronous(FULL)"},
+			"_txlock": {"immediate"},
+		}.Encode(),
+	}
+
+	db, err := sql.Open("sqlite", dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	r := &SQLiteRepository{db: db, logger: logger.With("component", "SQLiteRepository")}
+	if err := r.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Close closes the database.
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// migrate applies the migrations in migrations/ that have not been applied
+// yet, in order of their numeric prefix, each in its own transaction.
+func (r *SQLiteRepository) migrate(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("creating migrations table: %w", err)
+	}
+
+	var current int
+	if err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(path.Base(name), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return fmt.Errorf("migration %s: invalid version prefix", name)
+		}
+		if version <= current {
+			continue
+		}
+
+		script, err := migrations.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		err = r.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("applying migration %s: %w", name, err)
+		}
+		r.logger.Info("Applied migration", "version", version)
+	}
+
+	return nil
+}
+
+func (r *SQLiteRepository) CreateAccount(ctx context.Context, account domain.Account) error {
+	return r.Commit(ctx, ports.Changeset{NewAccounts: []domain.Account{account}})
+}
+
+func (r *SQLiteRepository) GetAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, owner, currency, balance, overdraft_limit, status, version
+		FROM accounts WHERE id = ?`, accountID)
+
+	account, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Account{}, domain.ErrInvalidAccountID
+	}
+	return account, err
+}
+
+func (r *SQLiteRepository) ListAccounts(ctx context.Context) ([]domain.Account, error) {
+	return listAccounts(ctx, r.db)
+}
+
+// QueryAccounts filters by visibility and status in SQL. Balances are
+// exact decimal strings and SQLite only folds ASCII case, so the owner
+// prefix and balance filters are applied to the rows as they are read;
+// every candidate row is read to compute the total.
+func (r *SQLiteRepository) QueryAccounts(ctx context.Context, query ports.AccountQuery) (ports.AccountPage, error) {
+	cursor, hasCursor, err := parseAccountCursor(query.Cursor)
+	if err != nil {
+		return ports.AccountPage{}, err
+	}
+
+	var conditions []string
+	var args []any
+	if p := query.VisibleTo; p != nil && !p.IsOperator() {
+		visible := "owner = ?"
+		args = append(args, p.Subject)
+		if len(p.AccountIDs) > 0 {
+			visible += " OR id IN (?" + strings.Repeat(", ?", len(p.AccountIDs)-1) + ")"
+			for _, id := range p.AccountIDs {
+				args = append(args, id)
+			}
+		}
+		conditions = append(conditions, "("+visible+")")
+	}
+	if query.Status != "" {
+		conditions, args = append(conditions, "status = ?"), append(args, query.Status)
+	}
+	clause := ""
+	if len(conditions) > 0 {
+		clause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, owner, currency, balance, overdraft_limit, status, version
+		FROM accounts `+clause+` ORDER BY id`, args...)
+	if err != nil {
+		return ports.AccountPage{}, err
+	}
+	defer rows.Close()
+
+	page := ports.AccountPage{Accounts: []domain.Account{}}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return ports.AccountPage{}, err
+		}
+		if !query.Matches(account) {
+			continue
+		}
+		page.Total++
+
+		if hasCursor && account.ID <= string(cursor) {
+			continue
+		}
+		switch {
+		case len(page.Accounts) < query.Limit:
+			page.Accounts = append(page.Accounts, account)
+		case page.NextCursor == "":
+			page.NextCursor = accountCursor(page.Accounts[len(page.Accounts)-1].ID).String()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return ports.AccountPage{}, err
+	}
+
+	return page, nil
+}
+
+func (r *SQLiteRepository) Record(ctx context.Context, account domain.Account, txn domain.Transaction) error {
+	return r.Commit(ctx, ports.Changeset{
+		Accounts:     []domain.Account{account},
+		Transactions: []domain.Transaction{txn},
+	})
+}
+
+func (r *SQLiteRepository) Commit(ctx context.Context, changes ports.Changeset) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		updated := make(map[string]bool, len(changes.NewAccounts)+len(changes.Accounts))
+		for _, account := range changes.NewAccounts {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO accounts (id, owner, currency, balance, overdraft_limit, status, version)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO NOTHING`,
+				account.ID, account.Owner, account.Currency, account.Balance.String(),
+				account.OverdraftLimit.String(), account.Status, account.Version)
+			if err := expectOneRow(res, err, domain.ErrAccountAlreadyExists); err != nil {
+				return err
+			}
+			updated[account.ID] = true
+		}
+
+		for _, account := range changes.Accounts {
+			if updated[account.ID] {
+				return domain.ErrVersionConflict
+			}
+
+			res, err := tx.ExecContext(ctx, `
+				UPDATE accounts
+				SET owner = ?, currency = ?, balance = ?, overdraft_limit = ?, status = ?, version = version + 1
+				WHERE id = ? AND version = ?`,
+				account.Owner, account.Currency, account.Balance.String(), account.OverdraftLimit.String(),
+				account.Status, account.ID, account.Version)
+			err = expectOneRow(res, err, domain.ErrVersionConflict)
+			if errors.Is(err, domain.ErrVersionConflict) {
+				// Tell a missing account apart from a stale one
+				if err := checkAccountExists(ctx, tx, account.ID); err != nil {
+					return err
+				}
+			}
+			if err != nil {
+				return err
+			}
+			updated[account.ID] = true
+		}
+
+		inChangeset := make(map[string]bool, len(changes.Transactions))
+		for _, txn := range changes.Transactions {
+			if !updated[txn.AccountID] {
+				return domain.ErrAccountTransactionMismatch
+			}
+			if err := insertTransaction(ctx, tx, txn); err != nil {
+				return err
+			}
+			inChangeset[txn.ID] = true
+		}
+
+		for _, transfer := range changes.Transfers {
+			if !inChangeset[transfer.Withdrawal.ID] || !inChangeset[transfer.Deposit.ID] {
+				return domain.ErrAccountTransactionMismatch
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO transfers (id, withdrawal_id, deposit_id, timestamp) VALUES (?, ?, ?, ?)`,
+				transfer.ID, transfer.Withdrawal.ID, transfer.Deposit.ID, transfer.Timestamp.UnixNano()); err != nil {
+				return err
+			}
+		}
+
+		for _, entry := range changes.Entries {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO ledger_entries (id, reference, account, direction, amount, currency, timestamp)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				entry.ID, entry.Reference, entry.Account, entry.Direction, entry.Amount.String(),
+				entry.Currency, entry.Timestamp.UnixNano()); err != nil {
+				return err
+			}
+		}
+
+		for _, event := range changes.AuditEvents {
+			if !updated[event.AccountID] {
+				return domain.ErrAccountTransactionMismatch
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO audit_events (id, account_id, action, old_value, new_value, timestamp)
+				VALUES (?, ?, ?, ?, ?, ?)`,
+				event.ID, event.AccountID, event.Action, event.OldValue, event.NewValue,
+				event.Timestamp.UnixNano()); err != nil {
+				return err
+			}
+		}
+
+		for _, key := range changes.APIKeys {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO api_keys (id, hash, subject, role, created_at) VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (hash) DO NOTHING`,
+				key.ID, key.Hash, key.Principal.Subject, key.Principal.Role, key.CreatedAt.UnixNano())
+			if err := expectOneRow(res, err, domain.ErrAPIKeyAlreadyExists); err != nil {
+				return err
+			}
+		}
+
+		decided := make(map[string]bool, len(changes.NewApprovals)+len(changes.Approvals))
+		for _, approval := range changes.NewApprovals {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO transfer_approvals
+					(id, from_account_id, to_account_id, amount, currency, status, requested_by, requested_at, transfer_id, version)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO NOTHING`,
+				approval.ID, approval.FromAccountID, approval.ToAccountID, approval.Amount.String(), approval.Currency,
+				approval.Status, approval.RequestedBy, approval.RequestedAt.UnixNano(), nullString(approval.TransferID),
+				approval.Version)
+			if err := expectOneRow(res, err, domain.ErrApprovalAlreadyExists); err != nil {
+				return err
+			}
+			if err := insertApprovalEvents(ctx, tx, approval); err != nil {
+				return err
+			}
+			decided[approval.ID] = true
+		}
+
+		for _, approval := range changes.Approvals {
+			if decided[approval.ID] {
+				return domain.ErrVersionConflict
+			}
+
+			res, err := tx.ExecContext(ctx, `
+				UPDATE transfer_approvals SET status = ?, transfer_id = ?, version = version + 1
+				WHERE id = ? AND version = ?`,
+				approval.Status, nullString(approval.TransferID), approval.ID, approval.Version)
+			err = expectOneRow(res, err, domain.ErrVersionConflict)
+			if errors.Is(err, domain.ErrVersionConflict) {
+				// Tell a missing approval apart from a stale one
+				var exists bool
+				if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM transfer_approvals WHERE id = ?)`,
+					approval.ID).Scan(&exists); err != nil {
+					return err
+				}
+				if !exists {
+					return domain.ErrApprovalNotFound
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if err := insertApprovalEvents(ctx, tx, approval); err != nil {
+				return err
+			}
+			decided[approval.ID] = true
+		}
+
+		return nil
+	})
+}
+
+func (r *SQLiteRepository) ListTransactions(ctx context.Context, accountID string) ([]domain.Transaction, error) {
+	if err := checkAccountExists(ctx, r.db, accountID); err != nil {
+		return nil, err
+	}
+	return queryTransactions(ctx, r.db, `WHERE account_id = ? ORDER BY timestamp, seq`, accountID)
+}
+
+// QueryTransactions pages through an account's transactions using the
+// (account_id, timestamp) index. Amounts are stored as exact decimal
+// strings, so the amount range is applied to the rows as they are read.
+func (r *SQLiteRepository) QueryTransactions(ctx context.Context, query ports.TransactionQuery) (ports.TransactionPage, error) {
+	cursor, hasCursor, err := parseTransactionCursor(query.Cursor)
+	if err != nil {
+		return ports.TransactionPage{}, err
+	}
+	if err := checkAccountExists(ctx, r.db, query.AccountID); err != nil {
+		return ports.TransactionPage{}, err
+	}
+
+	clause := []string{"account_id = ?"}
+	args := []any{query.AccountID}
+	if hasCursor {
+		clause = append(clause, "(timestamp, seq) > (?, ?)")
+		args = append(args, cursor.timestamp, cursor.seq)
+	}
+	if !query.From.IsZero() {
+		clause = append(clause, "timestamp >= ?")
+		args = append(args, query.From.UnixNano())
+	}
+	if !query.To.IsZero() {
+		clause = append(clause, "timestamp < ?")
+		args = append(args, query.To.UnixNano())
+	}
+	if query.Type != "" {
+		clause = append(clause, "type = ?")
+		args = append(args, query.Type)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+transactionColumns+` FROM transactions
+		WHERE `+strings.Join(clause, " AND ")+` ORDER BY timestamp, seq`, args...)
+	if err != nil {
+		return ports.TransactionPage{}, err
+	}
+	defer rows.Close()
+
+	page := ports.TransactionPage{Transactions: []domain.Transaction{}}
+	var last transactionCursor
+	for rows.Next() {
+		txn, seq, err := scanTransaction(rows)
+		if err != nil {
+			return ports.TransactionPage{}, err
+		}
+		if !query.Matches(txn) {
+			continue
+		}
+		if len(page.Transactions) == query.Limit {
+			page.NextCursor = last.String()
+			break
+		}
+		page.Transactions = append(page.Transactions, txn)
+		last = transactionCursor{timestamp: txn.Timestamp.UnixNano(), seq: seq}
+	}
+	if err := rows.Err(); err != nil {
+		return ports.TransactionPage{}, err
+	}
+
+	return page, nil
+}
+
+func (r *SQLiteRepository) GetTransfer(ctx context.Context, transferID string) (domain.Transfer, error) {
+	var transfer domain.Transfer
+	var withdrawalID, depositID string
+	var timestamp int64
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, withdrawal_id, deposit_id, timestamp FROM transfers WHERE id = ?`, transferID).
+		Scan(&transfer.ID, &withdrawalID, &depositID, &timestamp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Transfer{}, domain.ErrTransferNotFound
+	}
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+	transfer.Timestamp = fromUnixNano(timestamp)
+
+	legs, err := queryTransactions(ctx, r.db, `WHERE id IN (?, ?)`, withdrawalID, depositID)
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+	for _, leg := range legs {
+		switch leg.ID {
+		case withdrawalID:
+			transfer.Withdrawal = leg
+		case depositID:
+			transfer.Deposit = leg
+		}
+	}
+
+	return transfer, nil
+}
+
+func (r *SQLiteRepository) LedgerSnapshot(ctx context.Context) (ports.LedgerSnapshot, error) {
+	var snapshot ports.LedgerSnapshot
+
+	// Read accounts and entries in one transaction so they are consistent
+	err := r.inReadTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if snapshot.Accounts, err = listAccounts(ctx, tx); err != nil {
+			return err
+		}
+		snapshot.Entries, err = listLedgerEntries(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return ports.LedgerSnapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+func (r *SQLiteRepository) ListAuditEvents(ctx context.Context, accountID string) ([]domain.AuditEvent, error) {
+	if err := checkAccountExists(ctx, r.db, accountID); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, account_id, action, old_value, new_value, timestamp
+		FROM audit_events WHERE account_id = ? ORDER BY seq`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []domain.AuditEvent{}
+	for rows.Next() {
+		var event domain.AuditEvent
+		var timestamp int64
+		if err := rows.Scan(&event.ID, &event.AccountID, &event.Action, &event.OldValue, &event.NewValue, &timestamp); err != nil {
+			return nil, err
+		}
+		event.Timestamp = fromUnixNano(timestamp)
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *SQLiteRepository) GetAPIKey(ctx context.Context, hash string) (domain.APIKey, error) {
+	var key domain.APIKey
+	var createdAt int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, hash, subject, role, created_at FROM api_keys WHERE hash = ?`, hash).
+		Scan(&key.ID, &key.Hash, &key.Principal.Subject, &key.Principal.Role, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.APIKey{}, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	key.CreatedAt = fromUnixNano(createdAt)
+	return key, nil
+}
+
+func (r *SQLiteRepository) GetTransferApproval(ctx context.Context, approvalID string) (domain.TransferApproval, error) {
+	var approvals []domain.TransferApproval
+	err := r.inReadTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		approvals, err = queryApprovals(ctx, tx, `WHERE id = ?`, approvalID)
+		return err
+	})
+	if err != nil {
+		return domain.TransferApproval{}, err
+	}
+	if len(approvals) == 0 {
+		return domain.TransferApproval{}, domain.ErrApprovalNotFound
+	}
+
+	return approvals[0], nil
+}
+
+func (r *SQLiteRepository) ListTransferApprovals(ctx context.Context, status domain.ApprovalStatus) ([]domain.TransferApproval, error) {
+	clause, args := "", []any{}
+	if status != "" {
+		clause, args = `WHERE status = ?`, append(args, status)
+	}
+
+	var approvals []domain.TransferApproval
+	err := r.inReadTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		approvals, err = queryApprovals(ctx, tx, clause, args...)
+		return err
+	})
+	return approvals, err
+}
+
+// inTx runs fn in a write transaction, committing it if fn succeeds and
+// rolling it back otherwise. The transaction begins IMMEDIATE, taking the
+// write lock before fn runs.
+func (r *SQLiteRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return r.runTx(ctx, nil, fn)
+}
+
+// inReadTx runs fn in a deferred, read-only transaction, which sees a
+// consistent snapshot without taking the write lock.
+func (r *SQLiteRepository) inReadTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return r.runTx(ctx, &sql.TxOptions{ReadOnly: true}, fn)
+}
+
+func (r *SQLiteRepository) runTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// checkAccountExists returns domain.ErrInvalidAccountID if there is no
+// account with the given ID.
+func checkAccountExists(ctx context.Context, q querier, accountID string) error {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM accounts WHERE id = ?)`, accountID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrInvalidAccountID
+	}
+	return nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func listAccounts(ctx context.Context, q querier) ([]domain.Account, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, owner, currency, balance, overdraft_limit, status, version FROM accounts ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []domain.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+func scanAccount(row rowScanner) (domain.Account, error) {
+	var account domain.Account
+	var balance, overdraftLimit string
+	if err := row.Scan(&account.ID, &account.Owner, &account.Currency, &balance, &overdraftLimit, &account.Status, &account.Version); err != nil {
+		return domain.Account{}, err
+	}
+
+	var err error
+	if account.Balance, err = domain.ParseMoney(balance); err != nil {
+		return domain.Account{}, err
+	}
+	if account.OverdraftLimit, err = domain.ParseMoney(overdraftLimit); err != nil {
+		return domain.Account{}, err
+	}
+
+	return account, nil
+}
+
+func insertTransaction(ctx context.Context, tx *sql.Tx, txn domain.Transaction) error {
+	var conversion sql.NullString
+	if txn.Conversion != nil {
+		data, err := json.Marshal(txn.Conversion)
+		if err != nil {
+			return err
+		}
+		conversion = sql.NullString{String: string(data), Valid: true}
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO transactions
+			(id, account_id, type, amount, currency, conversion, transfer_id, counterparty_account_id, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		txn.ID, txn.AccountID, txn.Type, txn.Amount.String(), txn.Currency, conversion,
+		nullString(txn.TransferID), nullString(txn.CounterpartyID), txn.Timestamp.UnixNano())
+	return err
+}
+
+// transactionColumns are the columns read by scanTransaction.
+const transactionColumns = `id, account_id, type, amount, currency, conversion, transfer_id, counterparty_account_id, timestamp, seq`
+
+// queryTransactions returns the transactions selected by the given WHERE
+// (and ORDER BY) clause.
+func queryTransactions(ctx context.Context, q querier, clause string, args ...any) ([]domain.Transaction, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+transactionColumns+` FROM transactions `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []domain.Transaction{}
+	for rows.Next() {
+		txn, _, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, txn)
+	}
+	return transactions, rows.Err()
+}
+
+// scanTransaction reads a row of transactionColumns, returning the
+// transaction and its sequence number.
+func scanTransaction(row rowScanner) (domain.Transaction, int64, error) {
+	var txn domain.Transaction
+	var amount string
+	var conversion, transferID, counterpartyID sql.NullString
+	var timestamp, seq int64
+	if err := row.Scan(&txn.ID, &txn.AccountID, &txn.Type, &amount, &txn.Currency, &conversion,
+		&transferID, &counterpartyID, &timestamp, &seq); err != nil {
+		return domain.Transaction{}, 0, err
+	}
+
+	var err error
+	if txn.Amount, err = domain.ParseMoney(amount); err != nil {
+		return domain.Transaction{}, 0, err
+	}
+	if conversion.Valid {
+		txn.Conversion = new(domain.Conversion)
+		if err := json.Unmarshal([]byte(conversion.String), txn.Conversion); err != nil {
+			return domain.Transaction{}, 0, err
+		}
+	}
+	txn.TransferID = transferID.String
+	txn.CounterpartyID = counterpartyID.String
+	txn.Timestamp = fromUnixNano(timestamp)
+
+	return txn, seq, nil
+}
+
+func listLedgerEntries(ctx context.Context, q querier) ([]domain.LedgerEntry, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, reference, account, direction, amount, currency, timestamp
+		FROM ledger_entries ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []domain.LedgerEntry
+	for rows.Next() {
+		var entry domain.LedgerEntry
+		var amount string
+		var timestamp int64
+		if err := rows.Scan(&entry.ID, &entry.Reference, &entry.Account, &entry.Direction, &amount,
+			&entry.Currency, &timestamp); err != nil {
+			return nil, err
+		}
+
+		if entry.Amount, err = domain.ParseMoney(amount); err != nil {
+			return nil, err
+		}
+		entry.Timestamp = fromUnixNano(timestamp)
+
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// insertApprovalEvents stores the history of approval. Events that are
+// already stored are left as they are, since history is only appended to.
+func insertApprovalEvents(ctx context.Context, tx *sql.Tx, approval domain.TransferApproval) error {
+	for i, event := range approval.History {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO approval_events (approval_id, seq, action, actor, reason, timestamp)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (approval_id, seq) DO NOTHING`,
+			approval.ID, i, event.Action, event.Actor, event.Reason, event.Timestamp.UnixNano()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryApprovals returns the approvals selected by clause, with their
+// history, oldest request first. q should be a transaction, so that the
+// approvals and their history are consistent.
+func queryApprovals(ctx context.Context, q querier, clause string, args ...any) ([]domain.TransferApproval, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, from_account_id, to_account_id, amount, currency, status, requested_by, requested_at,
+			transfer_id, version
+		FROM transfer_approvals `+clause+` ORDER BY requested_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := []domain.TransferApproval{}
+	index := make(map[string]int)
+	for rows.Next() {
+		var approval domain.TransferApproval
+		var amount string
+		var requestedAt int64
+		var transferID sql.NullString
+		if err := rows.Scan(&approval.ID, &approval.FromAccountID, &approval.ToAccountID, &amount, &approval.Currency,
+			&approval.Status, &approval.RequestedBy, &requestedAt, &transferID, &approval.Version); err != nil {
+			return nil, err
+		}
+		if approval.Amount, err = domain.ParseMoney(amount); err != nil {
+			return nil, err
+		}
+		approval.RequestedAt = fromUnixNano(requestedAt)
+		approval.TransferID = transferID.String
+
+		index[approval.ID] = len(approvals)
+		approvals = append(approvals, approval)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	events, err := q.QueryContext(ctx, `
+		SELECT approval_id, action, actor, reason, timestamp FROM approval_events
+		WHERE approval_id IN (SELECT id FROM transfer_approvals `+clause+`)
+		ORDER BY approval_id, seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer events.Close()
+
+	for events.Next() {
+		var approvalID string
+		var event domain.ApprovalEvent
+		var timestamp int64
+		if err := events.Scan(&approvalID, &event.Action, &event.Actor, &event.Reason, &timestamp); err != nil {
+			return nil, err
+		}
+		event.Timestamp = fromUnixNano(timestamp)
+
+		if i, ok := index[approvalID]; ok {
+			approvals[i].History = append(approvals[i].History, event)
+		}
+	}
+	return approvals, events.Err()
+}
+
+// expectOneRow returns errNone if the statement succeeded but affected no rows.
+func expectOneRow(res sql.Result, err error, errNone error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errNone
+	}
+	return nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func fromUnixNano(ns int64) time.Time {
+	return time.Unix(0, ns).UTC()
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/storage/storagetest"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+func TestSQLiteRepository_Contract(t *testing.T) {
+	storagetest.RunRepositoryContract(t, func(t *testing.T) ports.Repository {
+		return openSQLiteRepository(t, filepath.Join(t.TempDir(), "bank.db"))
+	})
+}
+
+func openSQLiteRepository(t *testing.T, path string) *SQLiteRepository {
+	t.Helper()
+
+	repo, err := NewSQLiteRepository(path, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.NilError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+
+	return repo
+}
+
+func TestSQLiteRepository_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bank.db")
+	repo := openSQLiteRepository(t, path)
+	ctx := context.Background()
+
+	// Given: Two accounts in different currencies
+	from, _ := domain.NewAccount(domain.GetUUID(), "foo", "USD", domain.MustParseMoney("100"))
+	to, _ := domain.NewAccount(domain.GetUUID(), "bar", "EUR", domain.MustParseMoney("0"))
+	assert.NilError(t, repo.CreateAccount(ctx, from))
+	assert.NilError(t, repo.CreateAccount(ctx, to))
+
+	// And: A converted transfer between them
+	rate, _ := domain.NewExchangeRate("USD", "EUR", domain.MustParseRate("0.9234"), domain.GetTimeNow())
+	transfer, err := from.TransferWithConversion(&to, domain.MustParseMoney("40"), "USD", rate)
+	assert.NilError(t, err)
+	entries := append(
+		domain.PostTransaction(transfer.Withdrawal, domain.LedgerSuspense),
+		domain.PostTransaction(transfer.Deposit, domain.LedgerSuspense)...,
+	)
+
+	// When: It is committed
+	err = repo.Commit(ctx, ports.Changeset{
+		Accounts:     []domain.Account{from, to},
+		Transactions: []domain.Transaction{transfer.Withdrawal, transfer.Deposit},
+		Transfers:    []domain.Transfer{transfer},
+		Entries:      entries,
+	})
+	assert.NilError(t, err)
+
+	// Then: Everything should survive reopening the database
+	assert.NilError(t, repo.Close())
+	repo = openSQLiteRepository(t, path)
+
+	stored, err := repo.GetAccount(ctx, from.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, stored.Balance, domain.MustParseMoney("60.00"))
+	assert.Equal(t, stored.Version, int64(1))
+
+	fromTxns, err := repo.ListTransactions(ctx, from.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fromTxns, []domain.Transaction{transfer.Withdrawal})
+	toTxns, err := repo.ListTransactions(ctx, to.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, toTxns, []domain.Transaction{transfer.Deposit})
+
+	storedTransfer, err := repo.GetTransfer(ctx, transfer.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, storedTransfer, transfer)
+
+	snapshot, err := repo.LedgerSnapshot(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(snapshot.Accounts), 2)
+	assert.DeepEqual(t, snapshot.Entries, entries)
+
+	_, err = repo.GetTransfer(ctx, "non-existent-id")
+	assert.Assert(t, errors.Is(err, domain.ErrTransferNotFound))
+}
+
+func TestSQLiteRepository_MigrationsAreAppliedOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bank.db")
+
+	// Given: A migrated database
+	repo := openSQLiteRepository(t, path)
+	assert.NilError(t, repo.Close())
+
+	// When: It is opened again
+	repo = openSQLiteRepository(t, path)
+
+	// Then: Every migration should be recorded exactly once
+	var count int
+	err := repo.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+	assert.NilError(t, err)
+
+	names, _ := migrations.ReadDir("migrations")
+	assert.Equal(t, count, len(names))
+}
+
+func TestSQLiteRepository_PathWithURICharacters(t *testing.T) {
+	// Given: A path containing characters that are special in URIs
+	path := filepath.Join(t.TempDir(), "bank?#%.db")
+
+	// When: A database is created there and opened again
+	repo := openSQLiteRepository(t, path)
+	account, _ := domain.NewAccount(domain.GetUUID(), "foo", "USD", domain.MustParseMoney("100"))
+	assert.NilError(t, repo.CreateAccount(context.Background(), account))
+	assert.NilError(t, repo.Close())
+	repo = openSQLiteRepository(t, path)
+
+	// Then: It should be the same database, at exactly that path
+	_, err := repo.GetAccount(context.Background(), account.ID)
+	assert.NilError(t, err)
+	_, err = os.Stat(path)
+	assert.NilError(t, err)
+}
+
+func TestSQLiteRepository_ReadsDoNotWaitForWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bank.db")
+	repo := openSQLiteRepository(t, path)
+
+	// Given: A write transaction holding the database's write lock
+	writer, err := repo.db.BeginTx(context.Background(), nil)
+	assert.NilError(t, err)
+	defer func() { _ = writer.Rollback() }()
+	_, err = writer.Exec(`INSERT INTO schema_migrations (version) VALUES (-1)`)
+	assert.NilError(t, err)
+
+	// When: Reading in transactions meanwhile
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, snapshotErr := repo.LedgerSnapshot(ctx)
+	_, listErr := repo.ListTransferApprovals(ctx, "")
+	_, getErr := repo.GetTransferApproval(ctx, "non-existent-id")
+
+	// Then: The reads should not wait for the lock
+	assert.NilError(t, snapshotErr)
+	assert.NilError(t, listErr)
+	assert.Assert(t, errors.Is(getErr, domain.ErrApprovalNotFound))
+}
@@ -0,0 +1,900 @@
+// Package storagetest provides a conformance suite that every
+// ports.Repository implementation must pass.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+// Factory returns a new, empty repository for a single test. Any cleanup
+// should be registered with t.Cleanup.
+type Factory func(t *testing.T) ports.Repository
+
+// RunRepositoryContract runs the repository contract against the
+// repositories returned by newRepo, each case in its own subtest.
+func RunRepositoryContract(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo ports.Repository)
+	}{
+		{"CreateAndGetAccount", testCreateAndGetAccount},
+		{"CannotCreateDuplicateAccount", testCannotCreateDuplicateAccount},
+		{"GetNonExistentAccount", testGetNonExistentAccount},
+		{"ListAccounts", testListAccounts},
+		{"QueryAccountsPagesThroughAccounts", testQueryAccountsPagesThroughAccounts},
+		{"QueryAccountsFilters", testQueryAccountsFilters},
+		{"QueryAccountsInvalidCursor", testQueryAccountsInvalidCursor},
+		{"Record", testRecord},
+		{"RecordForNonExistentAccount", testRecordForNonExistentAccount},
+		{"RecordRejectsTransactionForOtherAccount", testRecordRejectsTransactionForOtherAccount},
+		{"RecordRejectsStaleVersion", testRecordRejectsStaleVersion},
+		{"ListTransactionsOrderedByTimestamp", testListTransactionsOrderedByTimestamp},
+		{"ReadsOfNonExistentAccount", testReadsOfNonExistentAccount},
+		{"ReadsHonourCancelledContext", testReadsHonourCancelledContext},
+		{"QueryTransactionsPagesThroughHistory", testQueryTransactionsPagesThroughHistory},
+		{"QueryTransactionsFilters", testQueryTransactionsFilters},
+		{"QueryTransactionsInvalidCursor", testQueryTransactionsInvalidCursor},
+		{"CommitTransfer", testCommitTransfer},
+		{"CommitIsAllOrNothing", testCommitIsAllOrNothing},
+		{"CommitRejectsTransferWithoutLegs", testCommitRejectsTransferWithoutLegs},
+		{"GetNonExistentTransfer", testGetNonExistentTransfer},
+		{"LedgerSnapshot", testLedgerSnapshot},
+		{"AuditEvents", testAuditEvents},
+		{"ConcurrentRecord", testConcurrentRecord},
+		{"APIKeys", testAPIKeys},
+		{"TransferApprovals", testTransferApprovals},
+		{"ApprovalVersionChecks", testApprovalVersionChecks},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepo(t))
+		})
+	}
+}
+
+// createAccount stores a new USD account with the given balance.
+func createAccount(t *testing.T, repo ports.Repository, owner, balance string) domain.Account {
+	t.Helper()
+
+	account, err := domain.NewAccount(domain.GetUUID(), owner, "USD", domain.MustParseMoney(balance))
+	assert.NilError(t, err)
+	assert.NilError(t, repo.CreateAccount(context.Background(), account))
+
+	return account
+}
+
+func testCreateAndGetAccount(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: A new account
+	expected, _ := domain.NewAccount(domain.GetUUID(), "foo", "USD", domain.MustParseMoney("100"))
+
+	// When: The account is created
+	assert.NilError(t, repo.CreateAccount(ctx, expected))
+
+	// Then: It should be retrievable unchanged
+	actual, err := repo.GetAccount(ctx, expected.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, actual, expected)
+}
+
+func testCannotCreateDuplicateAccount(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: An account already exists
+	existing := createAccount(t, repo, "foo", "50")
+
+	// When: Trying to create an account with the same ID
+	duplicate, _ := domain.NewAccount(existing.ID, "bar", "USD", domain.MustParseMoney("0"))
+	err := repo.CreateAccount(ctx, duplicate)
+
+	// Then: It should return an error indicating account already exists
+	assert.Assert(t, errors.Is(err, domain.ErrAccountAlreadyExists), "got %v", err)
+
+	// And: The original account should be kept
+	stored, err := repo.GetAccount(ctx, existing.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stored, existing)
+
+	// And: The same ID twice in one changeset should be rejected too
+	account, _ := domain.NewAccount(domain.GetUUID(), "baz", "USD", domain.MustParseMoney("0"))
+	err = repo.Commit(ctx, ports.Changeset{NewAccounts: []domain.Account{account, account}})
+	assert.Assert(t, errors.Is(err, domain.ErrAccountAlreadyExists), "got %v", err)
+
+	_, err = repo.GetAccount(ctx, account.ID)
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID), "got %v", err)
+}
+
+func testGetNonExistentAccount(t *testing.T, repo ports.Repository) {
+	// When: Getting an account that was never created
+	_, err := repo.GetAccount(context.Background(), "non-existent-id")
+
+	// Then: It should return an error indicating account not found
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID), "got %v", err)
+}
+
+func testListAccounts(t *testing.T, repo ports.Repository) {
+	// Given: No accounts
+	assert.Equal(t, len(listAccounts(t, repo)), 0)
+
+	// And then: Two accounts
+	account1 := createAccount(t, repo, "foo", "100")
+	account2 := createAccount(t, repo, "bar", "200")
+
+	// When: Listing accounts
+	accounts := listAccounts(t, repo)
+
+	// Then: Both accounts should be returned, ordered by ID
+	expected := []domain.Account{account1, account2}
+	if account2.ID < account1.ID {
+		expected = []domain.Account{account2, account1}
+	}
+	assert.DeepEqual(t, accounts, expected)
+}
+
+// listAccounts lists all accounts, failing the test on error.
+func listAccounts(t *testing.T, repo ports.Repository) []domain.Account {
+	t.Helper()
+
+	accounts, err := repo.ListAccounts(context.Background())
+	assert.NilError(t, err)
+	return accounts
+}
+
+// listTransactions lists an account's transactions, failing the test on
+// error.
+func listTransactions(t *testing.T, repo ports.Repository, accountID string) []domain.Transaction {
+	t.Helper()
+
+	transactions, err := repo.ListTransactions(context.Background(), accountID)
+	assert.NilError(t, err)
+	return transactions
+}
+
+// ledgerSnapshot reads the ledger, failing the test on error.
+func ledgerSnapshot(t *testing.T, repo ports.Repository) ports.LedgerSnapshot {
+	t.Helper()
+
+	snapshot, err := repo.LedgerSnapshot(context.Background())
+	assert.NilError(t, err)
+	return snapshot
+}
+
+// listAuditEvents lists an account's audit events, failing the test on
+// error.
+func listAuditEvents(t *testing.T, repo ports.Repository, accountID string) []domain.AuditEvent {
+	t.Helper()
+
+	events, err := repo.ListAuditEvents(context.Background(), accountID)
+	assert.NilError(t, err)
+	return events
+}
+
+// queryAllAccounts pages through the accounts matching query, returning
+// them together with the total reported by each page.
+func queryAllAccounts(t *testing.T, repo ports.Repository, query ports.AccountQuery) ([]domain.Account, []int) {
+	t.Helper()
+
+	var accounts []domain.Account
+	var totals []int
+	for {
+		page, err := repo.QueryAccounts(context.Background(), query)
+		assert.NilError(t, err)
+		accounts = append(accounts, page.Accounts...)
+		totals = append(totals, page.Total)
+		if page.NextCursor == "" {
+			return accounts, totals
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+func testQueryAccountsPagesThroughAccounts(t *testing.T, repo ports.Repository) {
+	// Given: Five accounts
+	var created []domain.Account
+	for range 5 {
+		created = append(created, createAccount(t, repo, "foo", "100"))
+	}
+	slices.SortFunc(created, func(a, b domain.Account) int { return strings.Compare(a.ID, b.ID) })
+
+	// When: Paging through them two at a time
+	accounts, totals := queryAllAccounts(t, repo, ports.AccountQuery{Limit: 2})
+
+	// Then: Every account should be returned once, ordered by ID
+	assert.DeepEqual(t, accounts, created)
+
+	// And: Every page should report the total
+	assert.DeepEqual(t, totals, []int{5, 5, 5})
+}
+
+func testQueryAccountsFilters(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: Accounts with different owners and balances, one of them frozen
+	alice := createAccount(t, repo, "Alice", "100")
+	alan := createAccount(t, repo, "alan", "300")
+	bob := createAccount(t, repo, "Bob", "500")
+	frozen := createAccount(t, repo, "Albert", "700")
+	assert.NilError(t, frozen.Freeze())
+	assert.NilError(t, repo.Commit(ctx, ports.Changeset{Accounts: []domain.Account{frozen}}))
+	frozen, _ = repo.GetAccount(ctx, frozen.ID)
+
+	low, high := domain.MustParseMoney("300"), domain.MustParseMoney("700")
+
+	tests := []struct {
+		name     string
+		query    ports.AccountQuery
+		expected []domain.Account
+	}{
+		{
+			name:     "Owner prefix ignoring case",
+			query:    ports.AccountQuery{OwnerPrefix: "AL"},
+			expected: []domain.Account{alice, alan, frozen},
+		},
+		{
+			name:     "Visible to a customer",
+			query:    ports.AccountQuery{VisibleTo: &domain.Principal{Subject: "Alice", Role: domain.RoleCustomer}},
+			expected: []domain.Account{alice},
+		},
+		{
+			name: "Visible to a customer granted other accounts",
+			query: ports.AccountQuery{VisibleTo: &domain.Principal{
+				Subject: "Alice", Role: domain.RoleCustomer, AccountIDs: []string{bob.ID, "non-existent-id"},
+			}},
+			expected: []domain.Account{alice, bob},
+		},
+		{
+			name:     "Visible to an operator",
+			query:    ports.AccountQuery{VisibleTo: &domain.Principal{Subject: "Olivia", Role: domain.RoleOperator}},
+			expected: []domain.Account{alice, alan, bob, frozen},
+		},
+		{
+			name:     "Status",
+			query:    ports.AccountQuery{Status: domain.StatusFrozen},
+			expected: []domain.Account{frozen},
+		},
+		{
+			name:     "Balance range",
+			query:    ports.AccountQuery{MinBalance: &low, MaxBalance: &high},
+			expected: []domain.Account{alan, bob, frozen},
+		},
+		{
+			name:     "Combined",
+			query:    ports.AccountQuery{OwnerPrefix: "al", Status: domain.StatusActive, MinBalance: &low},
+			expected: []domain.Account{alan},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Querying one account per page
+			query := tc.query
+			query.Limit = 1
+			accounts, totals := queryAllAccounts(t, repo, query)
+
+			// Then: Only matching accounts should be returned
+			expected := slices.SortedFunc(slices.Values(tc.expected), func(a, b domain.Account) int {
+				return strings.Compare(a.ID, b.ID)
+			})
+			assert.DeepEqual(t, accounts, expected)
+			assert.Equal(t, totals[0], len(expected))
+		})
+	}
+}
+
+func testQueryAccountsInvalidCursor(t *testing.T, repo ports.Repository) {
+	// When: Querying with a cursor that was not issued by the repository
+	_, err := repo.QueryAccounts(context.Background(), ports.AccountQuery{Cursor: "not a cursor", Limit: 10})
+
+	// Then: It should be rejected
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidCursor))
+}
+
+func testRecord(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: An existing account and a deposit
+	account := createAccount(t, repo, "foo", "100")
+	txn, _ := account.Deposit(domain.MustParseMoney("50"), "USD")
+
+	// When: The transaction is recorded
+	assert.NilError(t, repo.Record(ctx, account, txn))
+
+	// Then: It should appear in the list of transactions
+	assert.DeepEqual(t, listTransactions(t, repo, account.ID), []domain.Transaction{txn})
+
+	// And: The account should be updated and its version incremented
+	stored, err := repo.GetAccount(ctx, account.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stored.Balance, domain.MustParseMoney("150.00"))
+	assert.Equal(t, stored.Version, account.Version+1)
+}
+
+func testRecordForNonExistentAccount(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: An account that was never stored
+	account, _ := domain.NewAccount(domain.GetUUID(), "foo", "USD", domain.MustParseMoney("100"))
+	txn, _ := account.Deposit(domain.MustParseMoney("50"), "USD")
+
+	// When: Recording a transaction for it
+	err := repo.Record(ctx, account, txn)
+
+	// Then: It should return an error indicating account not found
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID), "got %v", err)
+
+	// And: The account should not have been created
+	_, err = repo.GetAccount(ctx, account.ID)
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID), "got %v", err)
+}
+
+func testRecordRejectsTransactionForOtherAccount(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: Two accounts, and a transaction on the second
+	account := createAccount(t, repo, "foo", "100")
+	other := createAccount(t, repo, "bar", "100")
+	txn, _ := other.Deposit(domain.MustParseMoney("50"), "USD")
+
+	// When: Recording it together with the first account
+	err := repo.Record(ctx, account, txn)
+
+	// Then: It should be rejected
+	assert.Assert(t, errors.Is(err, domain.ErrAccountTransactionMismatch), "got %v", err)
+
+	// And: Neither account should have changed
+	assert.Equal(t, len(listTransactions(t, repo, account.ID)), 0)
+	assert.Equal(t, len(listTransactions(t, repo, other.ID)), 0)
+	stored, _ := repo.GetAccount(ctx, account.ID)
+	assert.DeepEqual(t, stored, account)
+}
+
+func testRecordRejectsStaleVersion(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: An account read twice by competing writers
+	account := createAccount(t, repo, "foo", "100")
+	first, _ := repo.GetAccount(ctx, account.ID)
+	second, _ := repo.GetAccount(ctx, account.ID)
+
+	// When: The first writer commits a withdrawal
+	txn, _ := first.Withdraw(domain.MustParseMoney("80"), "USD")
+	assert.NilError(t, repo.Record(ctx, first, txn))
+
+	// Then: The second writer's update, based on the stale version, is rejected
+	txn, _ = second.Withdraw(domain.MustParseMoney("80"), "USD")
+	err := repo.Record(ctx, second, txn)
+	assert.Assert(t, errors.Is(err, domain.ErrVersionConflict), "got %v", err)
+
+	// And: The stored account reflects the single successful update
+	stored, _ := repo.GetAccount(ctx, account.ID)
+	assert.Equal(t, stored.Version, int64(1))
+	assert.DeepEqual(t, stored.Balance, domain.MustParseMoney("20.00"))
+	assert.Equal(t, len(listTransactions(t, repo, account.ID)), 1)
+}
+
+func testListTransactionsOrderedByTimestamp(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: Transactions recorded out of timestamp order, two of them with
+	// the same timestamp
+	account := createAccount(t, repo, "foo", "100")
+	base := time.Date(2025, 2, 12, 12, 0, 0, 0, time.UTC)
+
+	recorded := make([]domain.Transaction, 0, 4)
+	for _, offset := range []time.Duration{2 * time.Second, 0, time.Second, 0} {
+		account, _ = repo.GetAccount(ctx, account.ID)
+		txn, _ := account.Deposit(domain.MustParseMoney("1"), "USD")
+		txn.Timestamp = base.Add(offset)
+		assert.NilError(t, repo.Record(ctx, account, txn))
+		recorded = append(recorded, txn)
+	}
+
+	// When: Listing them
+	transactions := listTransactions(t, repo, account.ID)
+
+	// Then: They should be ordered by timestamp, ties in the order recorded
+	expected := []domain.Transaction{recorded[1], recorded[3], recorded[2], recorded[0]}
+	assert.DeepEqual(t, transactions, expected)
+}
+
+func testReadsOfNonExistentAccount(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// When: Reading the history of an account that does not exist
+	_, listErr := repo.ListTransactions(ctx, "non-existent-id")
+	_, queryErr := repo.QueryTransactions(ctx, ports.TransactionQuery{AccountID: "non-existent-id", Limit: 10})
+	_, auditErr := repo.ListAuditEvents(ctx, "non-existent-id")
+
+	// Then: Every read should fail with ErrInvalidAccountID
+	for _, err := range []error{listErr, queryErr, auditErr} {
+		assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID), "got %v", err)
+	}
+}
+
+func testReadsHonourCancelledContext(t *testing.T, repo ports.Repository) {
+	// Given: An account with a transaction
+	account := createAccount(t, repo, "foo", "100")
+	txn, _ := account.Deposit(domain.MustParseMoney("1"), "USD")
+	assert.NilError(t, repo.Record(context.Background(), account, txn))
+
+	// And: A cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reads := map[string]func() error{
+		"GetAccount": func() error {
+			_, err := repo.GetAccount(ctx, account.ID)
+			return err
+		},
+		"ListAccounts": func() error {
+			_, err := repo.ListAccounts(ctx)
+			return err
+		},
+		"QueryAccounts": func() error {
+			_, err := repo.QueryAccounts(ctx, ports.AccountQuery{Limit: 10})
+			return err
+		},
+		"ListTransactions": func() error {
+			_, err := repo.ListTransactions(ctx, account.ID)
+			return err
+		},
+		"QueryTransactions": func() error {
+			_, err := repo.QueryTransactions(ctx, ports.TransactionQuery{AccountID: account.ID, Limit: 10})
+			return err
+		},
+		"GetTransfer": func() error {
+			_, err := repo.GetTransfer(ctx, "non-existent-id")
+			return err
+		},
+		"LedgerSnapshot": func() error {
+			_, err := repo.LedgerSnapshot(ctx)
+			return err
+		},
+		"ListAuditEvents": func() error {
+			_, err := repo.ListAuditEvents(ctx, account.ID)
+			return err
+		},
+	}
+
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			// When: Reading with it
+			err := read()
+
+			// Then: The read should fail with the context's error
+			assert.Assert(t, errors.Is(err, context.Canceled), "got %v", err)
+		})
+	}
+}
+
+// recordHistory records a deposit of each amount, one second apart and in
+// that order, alternating with a withdrawal of 1.
+func recordHistory(t *testing.T, repo ports.Repository, account domain.Account, base time.Time, amounts ...string) []domain.Transaction {
+	t.Helper()
+	ctx := context.Background()
+
+	recorded := make([]domain.Transaction, 0, 2*len(amounts))
+	for i, amount := range amounts {
+		account, _ = repo.GetAccount(ctx, account.ID)
+		deposit, err := account.Deposit(domain.MustParseMoney(amount), "USD")
+		assert.NilError(t, err)
+		deposit.Timestamp = base.Add(time.Duration(2*i) * time.Second)
+		assert.NilError(t, repo.Record(ctx, account, deposit))
+
+		account, _ = repo.GetAccount(ctx, account.ID)
+		withdrawal, err := account.Withdraw(domain.MustParseMoney("1"), "USD")
+		assert.NilError(t, err)
+		withdrawal.Timestamp = base.Add(time.Duration(2*i+1) * time.Second)
+		assert.NilError(t, repo.Record(ctx, account, withdrawal))
+
+		recorded = append(recorded, deposit, withdrawal)
+	}
+	return recorded
+}
+
+func testQueryTransactionsPagesThroughHistory(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: An account with seven transactions, two of them sharing a
+	// timestamp, and another account with transactions of its own
+	account := createAccount(t, repo, "foo", "0")
+	base := time.Date(2025, 2, 12, 12, 0, 0, 0, time.UTC)
+	recorded := recordHistory(t, repo, account, base, "10", "20", "30")
+
+	account, _ = repo.GetAccount(ctx, account.ID)
+	tie, _ := account.Deposit(domain.MustParseMoney("5"), "USD")
+	tie.Timestamp = recorded[len(recorded)-1].Timestamp
+	assert.NilError(t, repo.Record(ctx, account, tie))
+	recorded = append(recorded, tie)
+
+	other := createAccount(t, repo, "bar", "0")
+	recordHistory(t, repo, other, base, "10")
+
+	// When: Paging through them three at a time
+	var pages [][]domain.Transaction
+	query := ports.TransactionQuery{AccountID: account.ID, Limit: 3}
+	for {
+		page, err := repo.QueryTransactions(ctx, query)
+		assert.NilError(t, err)
+		pages = append(pages, page.Transactions)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	// Then: Every transaction should be returned once, in order
+	expected := [][]domain.Transaction{recorded[0:3], recorded[3:6], recorded[6:7]}
+	assert.DeepEqual(t, pages, expected)
+}
+
+func testQueryTransactionsFilters(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: Deposits of 10, 20, 30 and 40, each followed by a withdrawal of 1
+	account := createAccount(t, repo, "foo", "0")
+	base := time.Date(2025, 2, 12, 12, 0, 0, 0, time.UTC)
+	recorded := recordHistory(t, repo, account, base, "10", "20", "30", "40")
+	low, high := domain.MustParseMoney("15"), domain.MustParseMoney("30")
+
+	tests := []struct {
+		name     string
+		query    ports.TransactionQuery
+		expected []domain.Transaction
+	}{
+		{
+			name:     "Date range",
+			query:    ports.TransactionQuery{From: base.Add(2 * time.Second), To: base.Add(5 * time.Second)},
+			expected: recorded[2:5],
+		},
+		{
+			name:     "Type",
+			query:    ports.TransactionQuery{Type: domain.Withdrawal},
+			expected: []domain.Transaction{recorded[1], recorded[3], recorded[5], recorded[7]},
+		},
+		{
+			name:     "Amount range",
+			query:    ports.TransactionQuery{MinAmount: &low, MaxAmount: &high},
+			expected: []domain.Transaction{recorded[2], recorded[4]},
+		},
+		{
+			name: "Combined",
+			query: ports.TransactionQuery{
+				From: base.Add(3 * time.Second), Type: domain.Deposit, MinAmount: &low,
+			},
+			expected: []domain.Transaction{recorded[4], recorded[6]},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Querying one transaction per page
+			var transactions []domain.Transaction
+			query := tc.query
+			query.AccountID, query.Limit = account.ID, 1
+			for {
+				page, err := repo.QueryTransactions(ctx, query)
+				assert.NilError(t, err)
+				transactions = append(transactions, page.Transactions...)
+				if page.NextCursor == "" {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+
+			// Then: Only matching transactions should be returned
+			assert.DeepEqual(t, transactions, tc.expected)
+		})
+	}
+}
+
+func testQueryTransactionsInvalidCursor(t *testing.T, repo ports.Repository) {
+	// When: Querying with a cursor that was not issued by the repository
+	_, err := repo.QueryTransactions(context.Background(), ports.TransactionQuery{
+		AccountID: "non-existent-id", Cursor: "not a cursor", Limit: 10,
+	})
+
+	// Then: It should be rejected
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidCursor))
+}
+
+func testCommitTransfer(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: Two accounts and a transfer between them
+	from := createAccount(t, repo, "foo", "100")
+	to := createAccount(t, repo, "bar", "0")
+	transfer, _ := from.Transfer(&to, domain.MustParseMoney("40"), "USD")
+
+	// When: Both legs are committed together
+	err := repo.Commit(ctx, ports.Changeset{
+		Accounts:     []domain.Account{from, to},
+		Transactions: []domain.Transaction{transfer.Withdrawal, transfer.Deposit},
+		Transfers:    []domain.Transfer{transfer},
+	})
+	assert.NilError(t, err)
+
+	// Then: Both accounts and transactions should be stored
+	storedFrom, _ := repo.GetAccount(ctx, from.ID)
+	storedTo, _ := repo.GetAccount(ctx, to.ID)
+	assert.DeepEqual(t, storedFrom.Balance, domain.MustParseMoney("60.00"))
+	assert.DeepEqual(t, storedTo.Balance, domain.MustParseMoney("40.00"))
+	assert.DeepEqual(t, listTransactions(t, repo, from.ID), []domain.Transaction{transfer.Withdrawal})
+	assert.DeepEqual(t, listTransactions(t, repo, to.ID), []domain.Transaction{transfer.Deposit})
+
+	// And: The transfer linking them should be retrievable
+	stored, err := repo.GetTransfer(ctx, transfer.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stored, transfer)
+}
+
+func testCommitIsAllOrNothing(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: One existing account and one that was never stored
+	original := createAccount(t, repo, "foo", "100")
+	missing, _ := domain.NewAccount(domain.GetUUID(), "bar", "USD", domain.MustParseMoney("0"))
+	from := original
+	transfer, _ := from.Transfer(&missing, domain.MustParseMoney("40"), "USD")
+
+	// When: Committing a transfer to the missing account
+	err := repo.Commit(ctx, ports.Changeset{
+		Accounts:     []domain.Account{from, missing},
+		Transactions: []domain.Transaction{transfer.Withdrawal, transfer.Deposit},
+		Transfers:    []domain.Transfer{transfer},
+		Entries:      domain.PostTransaction(transfer.Withdrawal, domain.LedgerSuspense),
+	})
+
+	// Then: The commit should fail
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID), "got %v", err)
+
+	// And: Nothing should have been stored
+	stored, _ := repo.GetAccount(ctx, from.ID)
+	assert.DeepEqual(t, stored, original)
+	assert.Equal(t, len(listTransactions(t, repo, from.ID)), 0)
+	assert.Equal(t, len(ledgerSnapshot(t, repo).Entries), 0)
+
+	_, err = repo.GetTransfer(ctx, transfer.ID)
+	assert.Assert(t, errors.Is(err, domain.ErrTransferNotFound), "got %v", err)
+}
+
+func testCommitRejectsTransferWithoutLegs(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: A transfer whose deposit leg is left out of the changeset
+	from := createAccount(t, repo, "foo", "100")
+	to := createAccount(t, repo, "bar", "0")
+	transfer, _ := from.Transfer(&to, domain.MustParseMoney("40"), "USD")
+
+	// When: Committing it
+	err := repo.Commit(ctx, ports.Changeset{
+		Accounts:     []domain.Account{from, to},
+		Transactions: []domain.Transaction{transfer.Withdrawal},
+		Transfers:    []domain.Transfer{transfer},
+	})
+
+	// Then: It should be rejected
+	assert.Assert(t, errors.Is(err, domain.ErrAccountTransactionMismatch), "got %v", err)
+	assert.Equal(t, len(listTransactions(t, repo, from.ID)), 0)
+}
+
+func testGetNonExistentTransfer(t *testing.T, repo ports.Repository) {
+	// When: Getting a transfer that was never committed
+	_, err := repo.GetTransfer(context.Background(), "non-existent-id")
+
+	// Then: It should return an error indicating transfer not found
+	assert.Assert(t, errors.Is(err, domain.ErrTransferNotFound), "got %v", err)
+}
+
+func testLedgerSnapshot(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: An account opened with ledger entries, and a later deposit
+	account, _ := domain.NewAccount(domain.GetUUID(), "foo", "USD", domain.MustParseMoney("100"))
+	opening := domain.OpeningEntries(account)
+	assert.NilError(t, repo.Commit(ctx, ports.Changeset{
+		NewAccounts: []domain.Account{account},
+		Entries:     opening,
+	}))
+
+	txn, _ := account.Deposit(domain.MustParseMoney("50"), "USD")
+	deposit := domain.PostTransaction(txn, domain.LedgerCash)
+	assert.NilError(t, repo.Commit(ctx, ports.Changeset{
+		Accounts:     []domain.Account{account},
+		Transactions: []domain.Transaction{txn},
+		Entries:      deposit,
+	}))
+
+	// When: Taking a ledger snapshot
+	snapshot := ledgerSnapshot(t, repo)
+
+	// Then: It should hold the account and all entries in commit order
+	assert.Equal(t, len(snapshot.Accounts), 1)
+	assert.DeepEqual(t, snapshot.Accounts[0].Balance, domain.MustParseMoney("150.00"))
+	assert.DeepEqual(t, snapshot.Entries, append(opening, deposit...))
+
+	// And: The ledger should balance
+	check, err := domain.CheckLedger(snapshot.Accounts, snapshot.Entries)
+	assert.NilError(t, err)
+	assert.Assert(t, check.Balanced)
+}
+
+func testAuditEvents(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: Two changes to an account's overdraft limit
+	account := createAccount(t, repo, "foo", "100")
+	var events []domain.AuditEvent
+	for _, limit := range []string{"50", "20"} {
+		account, _ = repo.GetAccount(ctx, account.ID)
+		event, err := account.SetOverdraftLimit(domain.MustParseMoney(limit))
+		assert.NilError(t, err)
+
+		assert.NilError(t, repo.Commit(ctx, ports.Changeset{
+			Accounts:    []domain.Account{account},
+			AuditEvents: []domain.AuditEvent{event},
+		}))
+		events = append(events, event)
+	}
+
+	// Then: Both events should be listed in order
+	assert.DeepEqual(t, listAuditEvents(t, repo, account.ID), events)
+
+	// And: An event for an account outside the changeset is rejected
+	other := createAccount(t, repo, "bar", "0")
+	account, _ = repo.GetAccount(ctx, account.ID)
+	err := repo.Commit(ctx, ports.Changeset{
+		Accounts:    []domain.Account{account},
+		AuditEvents: []domain.AuditEvent{domain.NewAuditEvent(other.ID, domain.AuditOverdraftLimitChanged, "0", "1")},
+	})
+	assert.Assert(t, errors.Is(err, domain.ErrAccountTransactionMismatch), "got %v", err)
+	assert.Equal(t, len(listAuditEvents(t, repo, other.ID)), 0)
+}
+
+func testConcurrentRecord(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: An account
+	account := createAccount(t, repo, "foo", "0")
+
+	// When: Many writers deposit concurrently, retrying on version conflicts
+	const writers = 20
+	var wg sync.WaitGroup
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				current, err := repo.GetAccount(ctx, account.ID)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				txn, _ := current.Deposit(domain.MustParseMoney("1"), "USD")
+				if err := repo.Record(ctx, current, txn); !errors.Is(err, domain.ErrVersionConflict) {
+					if err != nil {
+						t.Error(err)
+					}
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then: No deposit should be lost
+	stored, err := repo.GetAccount(ctx, account.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stored.Balance, domain.MustParseMoney("20.00"))
+	assert.Equal(t, stored.Version, int64(writers))
+	assert.Equal(t, len(listTransactions(t, repo, account.ID)), writers)
+}
+
+func testAPIKeys(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: A stored API key
+	key, err := domain.NewAPIKey("token", "Alice", domain.RoleCustomer)
+	assert.NilError(t, err)
+	assert.NilError(t, repo.Commit(ctx, ports.Changeset{APIKeys: []domain.APIKey{key}}))
+
+	// When: Looking it up by its hash
+	actual, err := repo.GetAPIKey(ctx, domain.HashAPIKeyToken("token"))
+
+	// Then: The key should be returned as stored
+	assert.NilError(t, err)
+	assert.Equal(t, actual.ID, key.ID)
+	assert.DeepEqual(t, actual.Principal, key.Principal)
+	assert.Assert(t, actual.CreatedAt.Equal(key.CreatedAt))
+
+	// And: A second key for the same token should be rejected
+	duplicate, err := domain.NewAPIKey("token", "Bob", domain.RoleAdmin)
+	assert.NilError(t, err)
+	err = repo.Commit(ctx, ports.Changeset{APIKeys: []domain.APIKey{duplicate}})
+	assert.Assert(t, errors.Is(err, domain.ErrAPIKeyAlreadyExists), "got %v", err)
+
+	// And: An unknown token should not be found
+	_, err = repo.GetAPIKey(ctx, domain.HashAPIKeyToken("other"))
+	assert.Assert(t, errors.Is(err, domain.ErrAPIKeyNotFound), "got %v", err)
+}
+
+func testTransferApprovals(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: Two pending approvals, requested one after the other
+	first := domain.NewTransferApproval("Alice", "from", "to", domain.MustParseMoney("5000"), "USD")
+	second := domain.NewTransferApproval("Bob", "from", "to", domain.MustParseMoney("7500.50"), "USD")
+	second.RequestedAt = first.RequestedAt.Add(time.Second)
+	assert.NilError(t, repo.Commit(ctx, ports.Changeset{NewApprovals: []domain.TransferApproval{second, first}}))
+
+	// When: The first is rejected
+	rejected := first
+	assert.NilError(t, rejected.Reject("Olivia", "unusual recipient"))
+	assert.NilError(t, repo.Commit(ctx, ports.Changeset{Approvals: []domain.TransferApproval{rejected}}))
+
+	// Then: It should be stored with its whole history
+	stored, err := repo.GetTransferApproval(ctx, first.ID)
+	assert.NilError(t, err)
+	rejected.Version++
+	assert.DeepEqual(t, stored, rejected)
+
+	// And: Approvals should be listed by status, oldest request first
+	pending, err := repo.ListTransferApprovals(ctx, domain.ApprovalPending)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, pending, []domain.TransferApproval{second})
+
+	all, err := repo.ListTransferApprovals(ctx, "")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, all, []domain.TransferApproval{rejected, second})
+
+	// And: An unknown approval should not be found
+	_, err = repo.GetTransferApproval(ctx, "non-existent-id")
+	assert.Assert(t, errors.Is(err, domain.ErrApprovalNotFound), "got %v", err)
+}
+
+func testApprovalVersionChecks(t *testing.T, repo ports.Repository) {
+	ctx := context.Background()
+
+	// Given: A stored approval
+	approval := domain.NewTransferApproval("Alice", "from", "to", domain.MustParseMoney("5000"), "USD")
+	assert.NilError(t, repo.Commit(ctx, ports.Changeset{NewApprovals: []domain.TransferApproval{approval}}))
+
+	// When: Two operators decide on the same version
+	approved, rejected := approval, approval
+	approved.History = slices.Clone(approval.History)
+	assert.NilError(t, approved.Approve("Olivia", ""))
+	assert.NilError(t, rejected.Reject("Oscar", ""))
+	assert.NilError(t, repo.Commit(ctx, ports.Changeset{Approvals: []domain.TransferApproval{approved}}))
+	err := repo.Commit(ctx, ports.Changeset{Approvals: []domain.TransferApproval{rejected}})
+
+	// Then: Only the first decision should be stored
+	assert.Assert(t, errors.Is(err, domain.ErrVersionConflict), "got %v", err)
+	stored, err := repo.GetTransferApproval(ctx, approval.ID)
+	assert.NilError(t, err)
+	assert.Equal(t, stored.Status, domain.ApprovalApproved)
+	assert.Equal(t, len(stored.History), 2)
+
+	// And: The approval cannot be created twice
+	err = repo.Commit(ctx, ports.Changeset{NewApprovals: []domain.TransferApproval{approval}})
+	assert.Assert(t, errors.Is(err, domain.ErrApprovalAlreadyExists), "got %v", err)
+
+	// And: An unknown approval cannot be updated
+	unknown := domain.NewTransferApproval("Alice", "from", "to", domain.MustParseMoney("5000"), "USD")
+	err = repo.Commit(ctx, ports.Changeset{Approvals: []domain.TransferApproval{unknown}})
+	assert.Assert(t, errors.Is(err, domain.ErrApprovalNotFound), "got %v", err)
+}
@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+const (
+	// walHeaderSize is the size of the frame header preceding every record:
+	// the payload length and its CRC-32C checksum, both little-endian uint32.
+	walHeaderSize = 8
+
+	// maxWALRecordSize bounds the payload of a record; a complete frame
+	// claiming a longer one is corrupt.
+	maxWALRecordSize = 64 << 20
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// errTornFrame is a frame left incomplete by a crash during an append:
+	// it is cut short by the end of the log, or is its final frame and
+	// fails its checksum.
+	errTornFrame = errors.New("torn log record")
+	// errCorruptFrame is a complete frame that fails its checksum while
+	// more data follows it. A crash cannot cause it, so the log is damaged.
+	errCorruptFrame = errors.New("corrupt log record")
+)
+
+// walRecord is a committed changeset as it is stored in the log. Seq
+// numbers are consecutive and continue across snapshots.
+type walRecord struct {
+	Seq          uint64                    `json:"seq"`
+	NewAccounts  []domain.Account          `json:"new_accounts,omitempty"`
+	Accounts     []domain.Account          `json:"accounts,omitempty"`
+	Transactions []domain.Transaction      `json:"transactions,omitempty"`
+	Transfers    []domain.Transfer         `json:"transfers,omitempty"`
+	Entries      []domain.LedgerEntry      `json:"entries,omitempty"`
+	AuditEvents  []domain.AuditEvent       `json:"audit_events,omitempty"`
+	APIKeys      []domain.APIKey           `json:"api_keys,omitempty"`
+	NewApprovals []domain.TransferApproval `json:"new_approvals,omitempty"`
+	Approvals    []domain.TransferApproval `json:"approvals,omitempty"`
+}
+
+func newWALRecord(seq uint64, changes ports.Changeset) walRecord {
+	return walRecord{
+		Seq:          seq,
+		NewAccounts:  changes.NewAccounts,
+		Accounts:     changes.Accounts,
+		Transactions: changes.Transactions,
+		Transfers:    changes.Transfers,
+		Entries:      changes.Entries,
+		AuditEvents:  changes.AuditEvents,
+		APIKeys:      changes.APIKeys,
+		NewApprovals: changes.NewApprovals,
+		Approvals:    changes.Approvals,
+	}
+}
+
+func (r walRecord) changeset() ports.Changeset {
+	return ports.Changeset{
+		NewAccounts:  r.NewAccounts,
+		Accounts:     r.Accounts,
+		Transactions: r.Transactions,
+		Transfers:    r.Transfers,
+		Entries:      r.Entries,
+		AuditEvents:  r.AuditEvents,
+		APIKeys:      r.APIKeys,
+		NewApprovals: r.NewApprovals,
+		Approvals:    r.Approvals,
+	}
+}
+
+// wal is an append-only log of framed records. Every append is fsync'd
+// before it returns.
+type wal struct {
+	file *os.File
+	size int64
+	// err is set when the log could not be restored to a consistent state
+	// after a failed write; all further appends fail with it.
+	err error
+}
+
+// openWAL opens (or creates) the log at path and reads all of its records.
+// A torn record at the end of the log, left behind by a crash during an
+// append, is truncated away; torn reports how many bytes were dropped. A
+// corrupt record before the end fails the open rather than dropping the
+// committed records after it.
+func openWAL(path string) (w *wal, records []walRecord, torn int64, err error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+		}
+	}()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var offset int64
+	for offset < int64(len(data)) {
+		payload, err := readFrame(data[offset:])
+		if errors.Is(err, errTornFrame) {
+			break
+		}
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("reading log record at offset %d: %w", offset, err)
+		}
+
+		var record walRecord
+		if err := json.Unmarshal(payload, &record); err != nil {
+			// The checksum matched, so this is not a torn write
+			return nil, nil, 0, fmt.Errorf("decoding log record at offset %d: %w", offset, err)
+		}
+		records = append(records, record)
+		offset += walHeaderSize + int64(len(payload))
+	}
+
+	if torn = int64(len(data)) - offset; torn > 0 {
+		if err := file.Truncate(offset); err != nil {
+			return nil, nil, 0, err
+		}
+		if err := file.Sync(); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, nil, 0, err
+	}
+
+	return &wal{file: file, size: offset}, records, torn, nil
+}
+
+// readFrame returns the payload of the frame at the start of data. It
+// fails with errTornFrame if the frame is incomplete, or fails its checksum
+// and ends data, and with errCorruptFrame if it is otherwise invalid.
+func readFrame(data []byte) ([]byte, error) {
+	if len(data) < walHeaderSize {
+		return nil, errTornFrame
+	}
+
+	length := binary.LittleEndian.Uint32(data[0:4])
+	checksum := binary.LittleEndian.Uint32(data[4:8])
+	end := walHeaderSize + int64(length)
+	switch {
+	case end > int64(len(data)):
+		return nil, errTornFrame
+	case length > maxWALRecordSize:
+		return nil, fmt.Errorf("%w: length %d exceeds maximum size", errCorruptFrame, length)
+	}
+
+	payload := data[walHeaderSize:end]
+	if crc32.Checksum(payload, crcTable) != checksum {
+		if end == int64(len(data)) {
+			return nil, errTornFrame
+		}
+		return nil, fmt.Errorf("%w: checksum mismatch", errCorruptFrame)
+	}
+	return payload, nil
+}
+
+// append writes record to the end of the log and syncs it to disk. If the
+// write fails, the partial frame is removed again.
+func (w *wal) append(record walRecord) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxWALRecordSize {
+		return fmt.Errorf("log record of %d bytes exceeds maximum size", len(payload))
+	}
+
+	var frame bytes.Buffer
+	frame.Grow(walHeaderSize + len(payload))
+	_ = binary.Write(&frame, binary.LittleEndian, uint32(len(payload)))
+	_ = binary.Write(&frame, binary.LittleEndian, crc32.Checksum(payload, crcTable))
+	frame.Write(payload)
+
+	if _, err := w.file.Write(frame.Bytes()); err != nil {
+		return w.rollback(err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return w.rollback(err)
+	}
+
+	w.size += int64(frame.Len())
+	return nil
+}
+
+// rollback truncates the log back to its last complete record after a
+// failed append.
+func (w *wal) rollback(cause error) error {
+	if err := w.file.Truncate(w.size); err != nil {
+		w.err = fmt.Errorf("log is inconsistent after failed write: %w", errors.Join(cause, err))
+		return w.err
+	}
+	if _, err := w.file.Seek(w.size, io.SeekStart); err != nil {
+		w.err = fmt.Errorf("log is inconsistent after failed write: %w", errors.Join(cause, err))
+		return w.err
+	}
+	return cause
+}
+
+// reset empties the log once its records are covered by a snapshot.
+func (w *wal) reset() error {
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w.size = 0
+	return w.file.Sync()
+}
+
+func (w *wal) close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,415 @@
+// Package config loads the settings of the banking service. Every setting
+// has a default, which a JSON config file, the environment and command-line
+// flags override in that order.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// Storage backends.
+const (
+	StorageMemory = "memory"
+	StorageFile   = "file"
+	StorageSQLite = "sqlite"
+)
+
+// Log formats.
+const (
+	LogText = "text"
+	LogJSON = "json"
+)
+
+// redacted replaces secrets when a Config is printed.
+const redacted = "[redacted]"
+
+// Config holds every setting of the service.
+type Config struct {
+	// ListenAddr is the TCP address the HTTP server listens on.
+	ListenAddr string   `json:"listen_addr"`
+	Log        Log      `json:"log"`
+	Storage    Storage  `json:"storage"`
+	Timeouts   Timeouts `json:"timeouts"`
+	Limits     Limits   `json:"limits"`
+	Auth       Auth     `json:"auth"`
+	// ExchangeRatesFile is a JSON file of exchange rates; without one only
+	// same-currency transfers are possible.
+	ExchangeRatesFile string `json:"exchange_rates_file,omitempty"`
+}
+
+// Log configures logging.
+type Log struct {
+	Level slog.Level `json:"level"`
+	// Format is LogText or LogJSON.
+	Format string `json:"format"`
+}
+
+// Storage selects the repository.
+type Storage struct {
+	// Backend is StorageMemory, StorageFile or StorageSQLite.
+	Backend string `json:"backend"`
+	// Path is the data directory of StorageFile, or the database file of
+	// StorageSQLite.
+	Path string `json:"path,omitempty"`
+	// SnapshotInterval is the number of commits after which StorageFile
+	// writes a snapshot; zero leaves it to the backend's default.
+	SnapshotInterval int `json:"snapshot_interval,omitempty"`
+}
+
+// Timeouts bound how long work may take; zero disables a timeout unless
+// noted otherwise.
+type Timeouts struct {
+	// Request is the deadline of every request.
+	Request Duration `json:"request"`
+	// ReadHeader bounds reading a request's headers.
+	ReadHeader Duration `json:"read_header"`
+	// Read bounds reading a whole request, body included.
+	Read Duration `json:"read"`
+	// Write bounds handling a request and writing its response; it must
+	// exceed Request, so that requests cut off by their deadline are
+	// still answered.
+	Write Duration `json:"write"`
+	// Idle bounds how long a keep-alive connection waits for its next
+	// request.
+	Idle Duration `json:"idle"`
+	// Shutdown bounds how long requests in flight are waited for on
+	// shutdown; it must be positive.
+	Shutdown Duration `json:"shutdown"`
+	// IdempotencyWindow is how long responses are kept for
+	// Idempotency-Key replays; it must be positive.
+	IdempotencyWindow Duration `json:"idempotency_window"`
+}
+
+// Limits configures business limits.
+type Limits struct {
+	// ApprovalThreshold is the amount, in ApprovalCurrency, above which a
+	// transfer must be approved by a second operator; zero disables
+	// approvals.
+	ApprovalThreshold domain.Money `json:"approval_threshold"`
+	// ApprovalCurrency is the currency of ApprovalThreshold. Transfers in
+	// other currencies are converted to it before they are compared.
+	ApprovalCurrency domain.Currency `json:"approval_currency,omitempty"`
+}
+
+// Auth configures authentication.
+type Auth struct {
+	// AdminAPIKey is registered as an admin key at startup.
+	AdminAPIKey string `json:"admin_api_key,omitempty"`
+	// JWKSFile is a JWKS file with the keys bearer tokens are verified
+	// against; without one bearer tokens are refused.
+	JWKSFile string `json:"jwks_file,omitempty"`
+	// JWTAudience must be named in the aud claim of bearer tokens.
+	JWTAudience string `json:"jwt_audience,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "30s".
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() Config {
+	return Config{
+		ListenAddr: ":8080",
+		Log:        Log{Level: slog.LevelInfo, Format: LogText},
+		Storage:    Storage{Backend: StorageMemory},
+		Timeouts: Timeouts{
+			Request:           Duration{30 * time.Second},
+			ReadHeader:        Duration{5 * time.Second},
+			Read:              Duration{15 * time.Second},
+			Write:             Duration{45 * time.Second},
+			Idle:              Duration{2 * time.Minute},
+			Shutdown:          Duration{30 * time.Second},
+			IdempotencyWindow: Duration{24 * time.Hour},
+		},
+	}
+}
+
+// setting is a value that can be set from the environment and, if it has
+// a flag name, from the command line.
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, v string) error
+}
+
+// settings are applied in order, so that later ones win when several are
+// given in the same layer. The admin key is deliberately not a flag, as
+// command lines are visible to every user of the host.
+var settings = []setting{
+	{env: "DATA_DIR", usage: "deprecated: storage backend file with this path", set: func(c *Config, v string) error {
+		c.Storage.Backend, c.Storage.Path = StorageFile, v
+		return nil
+	}},
+	{env: "SQLITE_PATH", usage: "deprecated: storage backend sqlite with this path", set: func(c *Config, v string) error {
+		c.Storage.Backend, c.Storage.Path = StorageSQLite, v
+		return nil
+	}},
+	{flag: "listen-addr", env: "LISTEN_ADDR", usage: "TCP address to listen on", set: func(c *Config, v string) error {
+		c.ListenAddr = v
+		return nil
+	}},
+	{flag: "log-level", env: "LOG_LEVEL", usage: "debug, info, warn or error", set: func(c *Config, v string) error {
+		return c.Log.Level.UnmarshalText([]byte(v))
+	}},
+	{flag: "log-format", env: "LOG_FORMAT", usage: "text or json", set: func(c *Config, v string) error {
+		c.Log.Format = v
+		return nil
+	}},
+	{flag: "storage-backend", env: "STORAGE_BACKEND", usage: "memory, file or sqlite", set: func(c *Config, v string) error {
+		c.Storage.Backend = v
+		return nil
+	}},
+	{flag: "storage-path", env: "STORAGE_PATH", usage: "data directory (file) or database file (sqlite)", set: func(c *Config, v string) error {
+		c.Storage.Path = v
+		return nil
+	}},
+	{flag: "snapshot-interval", env: "SNAPSHOT_INTERVAL", usage: "commits between snapshots of the file backend", set: func(c *Config, v string) (err error) {
+		c.Storage.SnapshotInterval, err = strconv.Atoi(v)
+		return err
+	}},
+	{flag: "request-timeout", env: "REQUEST_TIMEOUT", usage: "deadline of every request; 0 disables it", set: func(c *Config, v string) error {
+		return c.Timeouts.Request.UnmarshalText([]byte(v))
+	}},
+	{flag: "read-header-timeout", env: "READ_HEADER_TIMEOUT", usage: "time to read a request's headers; 0 disables it", set: func(c *Config, v string) error {
+		return c.Timeouts.ReadHeader.UnmarshalText([]byte(v))
+	}},
+	{flag: "read-timeout", env: "READ_TIMEOUT", usage: "time to read a whole request; 0 disables it", set: func(c *Config, v string) error {
+		return c.Timeouts.Read.UnmarshalText([]byte(v))
+	}},
+	{flag: "write-timeout", env: "WRITE_TIMEOUT", usage: "time to handle a request and write its response; 0 disables it", set: func(c *Config, v string) error {
+		return c.Timeouts.Write.UnmarshalText([]byte(v))
+	}},
+	{flag: "idle-timeout", env: "IDLE_TIMEOUT", usage: "time a keep-alive connection waits for its next request; 0 disables it", set: func(c *Config, v string) error {
+		return c.Timeouts.Idle.UnmarshalText([]byte(v))
+	}},
+	{flag: "shutdown-timeout", env: "SHUTDOWN_TIMEOUT", usage: "time requests in flight are waited for on shutdown", set: func(c *Config, v string) error {
+		return c.Timeouts.Shutdown.UnmarshalText([]byte(v))
+	}},
+	{flag: "idempotency-window", env: "IDEMPOTENCY_WINDOW", usage: "how long responses are kept for Idempotency-Key replays", set: func(c *Config, v string) error {
+		return c.Timeouts.IdempotencyWindow.UnmarshalText([]byte(v))
+	}},
+	{flag: "approval-threshold", env: "APPROVAL_THRESHOLD", usage: "amount above which transfers need approval; 0 disables approvals", set: func(c *Config, v string) error {
+		return c.Limits.ApprovalThreshold.UnmarshalText([]byte(v))
+	}},
+	{flag: "approval-currency", env: "APPROVAL_CURRENCY", usage: "currency of the approval threshold", set: func(c *Config, v string) error {
+		c.Limits.ApprovalCurrency = domain.Currency(v)
+		return nil
+	}},
+	{env: "ADMIN_API_KEY", usage: "admin key registered at startup", set: func(c *Config, v string) error {
+		c.Auth.AdminAPIKey = v
+		return nil
+	}},
+	{flag: "jwks-file", env: "JWT_JWKS_FILE", usage: "JWKS file to verify bearer tokens against", set: func(c *Config, v string) error {
+		c.Auth.JWKSFile = v
+		return nil
+	}},
+	{flag: "jwt-audience", env: "JWT_AUDIENCE", usage: "audience bearer tokens must be issued for", set: func(c *Config, v string) error {
+		c.Auth.JWTAudience = v
+		return nil
+	}},
+	{flag: "exchange-rates-file", env: "EXCHANGE_RATES_FILE", usage: "JSON file of exchange rates", set: func(c *Config, v string) error {
+		c.ExchangeRatesFile = v
+		return nil
+	}},
+}
+
+// Options are the command-line flags that are not settings.
+type Options struct {
+	// PrintConfig asks for the effective config to be printed instead of
+	// starting the service.
+	PrintConfig bool
+}
+
+// Load returns the config given by args, the command-line arguments
+// without the program name, and the environment as seen through
+// lookupEnv. The config file is named by the -config flag, or else by
+// CONFIG_FILE. The result is validated. Flag errors and -help are written
+// to standard error; -help fails with flag.ErrHelp.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, Options, error) {
+	var opts Options
+	var configFile string
+
+	fs := flag.NewFlagSet("banking-service", flag.ContinueOnError)
+	fs.StringVar(&configFile, "config", "", "JSON config file (env CONFIG_FILE)")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective config and exit")
+	for _, s := range settings {
+		if s.flag != "" {
+			fs.String(s.flag, "", fmt.Sprintf("%s (env %s)", s.usage, s.env))
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, Options{}, err
+	}
+	if fs.NArg() > 0 {
+		return Config{}, Options{}, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	cfg := Default()
+
+	if configFile == "" {
+		configFile, _ = lookupEnv("CONFIG_FILE")
+	}
+	if configFile != "" {
+		if err := cfg.readFile(configFile); err != nil {
+			return Config{}, Options{}, err
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := lookupEnv(s.env); ok && v != "" {
+			if err := s.set(&cfg, v); err != nil {
+				return Config{}, Options{}, fmt.Errorf("invalid %s %q: %w", s.env, v, err)
+			}
+		}
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name && err == nil {
+				if setErr := s.set(&cfg, f.Value.String()); setErr != nil {
+					err = fmt.Errorf("invalid -%s %q: %w", s.flag, f.Value, setErr)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return Config{}, Options{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, Options{}, err
+	}
+	return cfg, opts, nil
+}
+
+// readFile overrides c with the settings in a JSON config file. Settings
+// the file leaves out keep their values; unknown settings are an error.
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting of c.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+		invalid("listen_addr: %v", err)
+	}
+
+	if c.Log.Format != LogText && c.Log.Format != LogJSON {
+		invalid("log.format: must be %q or %q", LogText, LogJSON)
+	}
+
+	switch c.Storage.Backend {
+	case StorageMemory:
+	case StorageFile, StorageSQLite:
+		if c.Storage.Path == "" {
+			invalid("storage.path: required by the %s backend", c.Storage.Backend)
+		}
+	default:
+		invalid("storage.backend: must be %q, %q or %q", StorageMemory, StorageFile, StorageSQLite)
+	}
+	if c.Storage.SnapshotInterval < 0 {
+		invalid("storage.snapshot_interval: must not be negative")
+	}
+
+	for _, t := range []struct {
+		name string
+		d    Duration
+	}{
+		{"timeouts.request", c.Timeouts.Request},
+		{"timeouts.read_header", c.Timeouts.ReadHeader},
+		{"timeouts.read", c.Timeouts.Read},
+		{"timeouts.write", c.Timeouts.Write},
+		{"timeouts.idle", c.Timeouts.Idle},
+	} {
+		if t.d.Duration < 0 {
+			invalid("%s: must not be negative", t.name)
+		}
+	}
+	if w, r := c.Timeouts.Write.Duration, c.Timeouts.Request.Duration; w > 0 && (r <= 0 || w <= r) {
+		invalid("timeouts.write: must exceed timeouts.request, which must be set along with it")
+	}
+	if c.Timeouts.Shutdown.Duration <= 0 {
+		invalid("timeouts.shutdown: must be positive")
+	}
+	if c.Timeouts.IdempotencyWindow.Duration <= 0 {
+		invalid("timeouts.idempotency_window: must be positive")
+	}
+
+	if c.Limits.ApprovalThreshold.IsNegative() {
+		invalid("limits.approval_threshold: must not be negative")
+	}
+	if c.Limits.ApprovalThreshold.IsPositive() && c.Limits.ApprovalCurrency.Validate() != nil {
+		invalid("limits.approval_currency: must be a supported currency when limits.approval_threshold is set")
+	}
+
+	if c.Auth.JWKSFile != "" && c.Auth.JWTAudience == "" {
+		invalid("auth.jwt_audience: required with auth.jwks_file")
+	}
+
+	return errors.Join(errs...)
+}
+
+// Print writes c to w as a JSON config file, with secrets redacted.
+func (c Config) Print(w io.Writer) error {
+	if c.Auth.AdminAPIKey != "" {
+		c.Auth.AdminAPIKey = redacted
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// NewLogger returns a logger writing to w in the configured format and at
+// the configured level.
+func (l Log) NewLogger(w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: l.Level}
+	if l.Format == LogJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
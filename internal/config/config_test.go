@@ -0,0 +1,286 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+// env returns a lookupEnv function that sees only vars.
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+// writeFile writes a config file and returns its path.
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	// When: Loading without any settings
+	cfg, opts, err := Load(nil, env(nil))
+
+	// Then: The defaults apply
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cfg, Default())
+	assert.Equal(t, opts.PrintConfig, false)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	// Given: A config file that sets everything it can
+	path := writeFile(t, `{
+		"listen_addr": ":9000",
+		"log": {"level": "debug", "format": "json"},
+		"storage": {"backend": "file", "path": "/var/lib/bank", "snapshot_interval": 10},
+		"timeouts": {"request": "5s", "idempotency_window": "1h"},
+		"limits": {"approval_threshold": "10000", "approval_currency": "USD"}
+	}`)
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want func(c *Config)
+	}{
+		{
+			name: "File over defaults",
+			args: []string{"-config", path},
+			want: func(c *Config) {},
+		},
+		{
+			name: "File named in the environment",
+			env:  map[string]string{"CONFIG_FILE": path},
+			want: func(c *Config) {},
+		},
+		{
+			name: "Environment over file",
+			args: []string{"-config", path},
+			env:  map[string]string{"LISTEN_ADDR": ":9001", "LOG_LEVEL": "warn", "REQUEST_TIMEOUT": "10s"},
+			want: func(c *Config) {
+				c.ListenAddr = ":9001"
+				c.Log.Level = slog.LevelWarn
+				c.Timeouts.Request = Duration{10 * time.Second}
+			},
+		},
+		{
+			name: "Flags over environment",
+			args: []string{"-config", path, "-listen-addr", ":9002", "-approval-threshold", "500", "-approval-currency", "EUR"},
+			env:  map[string]string{"LISTEN_ADDR": ":9001", "APPROVAL_CURRENCY": "JPY"},
+			want: func(c *Config) {
+				c.ListenAddr = ":9002"
+				c.Limits.ApprovalThreshold = domain.MustParseMoney("500")
+				c.Limits.ApprovalCurrency = "EUR"
+			},
+		},
+		{
+			name: "Empty environment variables are ignored",
+			args: []string{"-config", path},
+			env:  map[string]string{"LISTEN_ADDR": ""},
+			want: func(c *Config) {},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Loading the layers
+			cfg, _, err := Load(tc.args, env(tc.env))
+			assert.NilError(t, err)
+
+			// Then: Every setting comes from the highest layer that sets it
+			want := Default()
+			want.ListenAddr = ":9000"
+			want.Log = Log{Level: slog.LevelDebug, Format: LogJSON}
+			want.Storage = Storage{Backend: StorageFile, Path: "/var/lib/bank", SnapshotInterval: 10}
+			want.Timeouts.Request = Duration{5 * time.Second}
+			want.Timeouts.IdempotencyWindow = Duration{time.Hour}
+			want.Limits = Limits{ApprovalThreshold: domain.MustParseMoney("10000"), ApprovalCurrency: "USD"}
+			tc.want(&want)
+			assert.DeepEqual(t, cfg, want)
+		})
+	}
+}
+
+func TestLoad_LegacyStorageVariables(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Storage
+	}{
+		{
+			name: "DATA_DIR",
+			env:  map[string]string{"DATA_DIR": "/data"},
+			want: Storage{Backend: StorageFile, Path: "/data"},
+		},
+		{
+			name: "SQLITE_PATH over DATA_DIR",
+			env:  map[string]string{"DATA_DIR": "/data", "SQLITE_PATH": "/bank.db"},
+			want: Storage{Backend: StorageSQLite, Path: "/bank.db"},
+		},
+		{
+			name: "STORAGE_BACKEND over SQLITE_PATH",
+			env:  map[string]string{"SQLITE_PATH": "/bank.db", "STORAGE_BACKEND": "memory"},
+			want: Storage{Backend: StorageMemory, Path: "/bank.db"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Loading the environment
+			cfg, _, err := Load(nil, env(tc.env))
+			assert.NilError(t, err)
+
+			// Then: The storage is selected as before
+			assert.DeepEqual(t, cfg.Storage, tc.want)
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string
+		wantErr []string
+	}{
+		{
+			name:    "Unknown flag",
+			args:    []string{"-listen", ":80"},
+			wantErr: []string{"flag provided but not defined"},
+		},
+		{
+			name:    "Unparsable environment variable",
+			env:     map[string]string{"REQUEST_TIMEOUT": "soon"},
+			wantErr: []string{"REQUEST_TIMEOUT"},
+		},
+		{
+			name:    "Unparsable flag",
+			args:    []string{"-log-level", "loud"},
+			wantErr: []string{"-log-level"},
+		},
+		{
+			name:    "Unknown setting in file",
+			file:    `{"listen_address": ":80"}`,
+			wantErr: []string{"listen_address"},
+		},
+		{
+			name:    "Missing config file",
+			env:     map[string]string{"CONFIG_FILE": "/does/not/exist.json"},
+			wantErr: []string{"failed to read config file"},
+		},
+		{
+			name: "Every invalid setting is reported",
+			env: map[string]string{
+				"LISTEN_ADDR":        "8080",
+				"LOG_FORMAT":         "xml",
+				"STORAGE_BACKEND":    "sqlite",
+				"IDEMPOTENCY_WINDOW": "0s",
+				"APPROVAL_THRESHOLD": "-1",
+				"JWT_JWKS_FILE":      "jwks.json",
+			},
+			wantErr: []string{
+				"listen_addr",
+				"log.format",
+				"storage.path",
+				"timeouts.idempotency_window",
+				"limits.approval_threshold",
+				"auth.jwt_audience",
+			},
+		},
+		{
+			name:    "Write timeout not above request timeout",
+			env:     map[string]string{"REQUEST_TIMEOUT": "1m", "WRITE_TIMEOUT": "1m"},
+			wantErr: []string{"timeouts.write"},
+		},
+		{
+			name:    "Write timeout without request timeout",
+			env:     map[string]string{"REQUEST_TIMEOUT": "0s"},
+			wantErr: []string{"timeouts.write"},
+		},
+		{
+			name:    "Negative or missing server timeouts",
+			env:     map[string]string{"IDLE_TIMEOUT": "-1s", "SHUTDOWN_TIMEOUT": "0s"},
+			wantErr: []string{"timeouts.idle", "timeouts.shutdown"},
+		},
+		{
+			name:    "Approval threshold without a currency",
+			env:     map[string]string{"APPROVAL_THRESHOLD": "10000"},
+			wantErr: []string{"limits.approval_currency"},
+		},
+		{
+			name:    "Approval threshold in an unknown currency",
+			env:     map[string]string{"APPROVAL_THRESHOLD": "10000", "APPROVAL_CURRENCY": "XYZ"},
+			wantErr: []string{"limits.approval_currency"},
+		},
+		{
+			name:    "Negative snapshot interval",
+			env:     map[string]string{"SNAPSHOT_INTERVAL": "-1"},
+			wantErr: []string{"storage.snapshot_interval"},
+		},
+		{
+			name:    "Unknown backend",
+			env:     map[string]string{"STORAGE_BACKEND": "postgres"},
+			wantErr: []string{"storage.backend"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := tc.args
+			if tc.file != "" {
+				args = append(args, "-config", writeFile(t, tc.file))
+			}
+
+			// When: Loading an invalid config
+			_, _, err := Load(args, env(tc.env))
+
+			// Then: Loading fails and names what is wrong
+			assert.Assert(t, err != nil)
+			for _, want := range tc.wantErr {
+				assert.Assert(t, strings.Contains(err.Error(), want), "%q does not mention %q", err, want)
+			}
+		})
+	}
+}
+
+func TestConfig_Print(t *testing.T) {
+	// Given: A config with a secret
+	cfg, opts, err := Load([]string{"-print-config"}, env(map[string]string{"ADMIN_API_KEY": "secret-admin-key"}))
+	assert.NilError(t, err)
+	assert.Assert(t, opts.PrintConfig)
+
+	// When: Printing it
+	var out bytes.Buffer
+	assert.NilError(t, cfg.Print(&out))
+
+	// Then: The secret is redacted
+	assert.Assert(t, !strings.Contains(out.String(), "secret-admin-key"))
+	assert.Assert(t, strings.Contains(out.String(), redacted))
+
+	// And: The output can be loaded again as a config file
+	loaded, _, err := Load([]string{"-config", writeFile(t, out.String())}, env(nil))
+	assert.NilError(t, err)
+	cfg.Auth.AdminAPIKey = redacted
+	assert.DeepEqual(t, loaded, cfg)
+
+	var printed map[string]any
+	assert.NilError(t, json.Unmarshal(out.Bytes(), &printed))
+	assert.Equal(t, printed["listen_addr"], ":8080")
+}
@@ -1,78 +1,325 @@
 package domain
 
+// AccountStatus is the lifecycle state of an account.
+type AccountStatus string
+
+const (
+	// StatusActive accounts accept all operations.
+	StatusActive AccountStatus = "active"
+	// StatusFrozen accounts may receive funds but not send them.
+	StatusFrozen AccountStatus = "frozen"
+	// StatusClosed accounts have a zero balance and reject all operations.
+	// Closing an account is final.
+	StatusClosed AccountStatus = "closed"
+)
+
 // Account represents a bank account entity.
+//
+// The balance may go down to minus OverdraftLimit. Version is incremented
+// by the repository on every committed update and is used to detect
+// concurrent modifications (optimistic locking).
 type Account struct {
-	ID      string  `json:"id"`
-	Owner   string  `json:"owner"`
-	Balance float64 `json:"balance"`
+	ID             string        `json:"id"`
+	Owner          string        `json:"owner"`
+	Currency       Currency      `json:"currency"`
+	Balance        Money         `json:"balance"`
+	OverdraftLimit Money         `json:"overdraft_limit"`
+	Status         AccountStatus `json:"status"`
+	Version        int64         `json:"version"`
 }
 
-func NewAccount(ID string, owner string, initialBalance float64) (Account, error) {
+func NewAccount(ID string, owner string, currency Currency, initialBalance Money) (Account, error) {
 	if ID == "" {
 		return Account{}, ErrInvalidAccountID
 	}
 	if owner == "" {
 		return Account{}, ErrInvalidOwner
 	}
-	if initialBalance < 0 {
+	if initialBalance.IsNegative() {
 		return Account{}, ErrNegativeBalance
 	}
 
+	balance, err := currency.normalize(initialBalance)
+	if err != nil {
+		return Account{}, err
+	}
+	overdraftLimit, err := currency.normalize(Money{})
+	if err != nil {
+		return Account{}, err
+	}
+
 	return Account{
-		ID:      ID,
-		Owner:   owner,
-		Balance: initialBalance,
+		ID:             ID,
+		Owner:          owner,
+		Currency:       currency,
+		Balance:        balance,
+		OverdraftLimit: overdraftLimit,
+		Status:         StatusActive,
 	}, nil
 }
 
-func (a *Account) Deposit(amount float64) (Transaction, error) {
-	if amount <= 0 {
-		return Transaction{}, ErrInvalidAmount
+// SetOverdraftLimit changes how far below zero the balance may go. The
+// limit cannot be lowered below the overdraft currently in use.
+func (a *Account) SetOverdraftLimit(limit Money) (AuditEvent, error) {
+	if a.Status == StatusClosed {
+		return AuditEvent{}, ErrAccountClosed
+	}
+	if limit.IsNegative() {
+		return AuditEvent{}, ErrInvalidOverdraftLimit
 	}
 
-	a.Balance += amount
+	limit, err := a.Currency.normalize(limit)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+	if limit.Neg().Cmp(a.Balance) > 0 {
+		return AuditEvent{}, ErrOverdraftInUse
+	}
 
-	return NewTransaction(a.ID, Deposit, amount)
+	previous := a.OverdraftLimit
+	a.OverdraftLimit = limit
+
+	return NewAuditEvent(a.ID, AuditOverdraftLimitChanged, previous.String(), limit.String()), nil
 }
 
-func (a *Account) Withdraw(amount float64) (Transaction, error) {
-	if amount <= 0 {
-		return Transaction{}, ErrInvalidAmount
+// Freeze stops the account from sending funds until it is unfrozen.
+func (a *Account) Freeze() error {
+	switch a.Status {
+	case StatusClosed:
+		return ErrAccountClosed
+	case StatusFrozen:
+		return ErrInvalidStatusTransition
 	}
-	if amount > a.Balance {
-		return Transaction{}, ErrInsufficientFunds
+
+	a.Status = StatusFrozen
+	return nil
+}
+
+// Unfreeze makes a frozen account active again.
+func (a *Account) Unfreeze() error {
+	switch a.Status {
+	case StatusClosed:
+		return ErrAccountClosed
+	case StatusFrozen:
+		a.Status = StatusActive
+		return nil
+	default:
+		return ErrInvalidStatusTransition
 	}
+}
 
-	a.Balance -= amount
+// Close permanently closes the account. Only accounts with a zero balance
+// can be closed.
+func (a *Account) Close() error {
+	if a.Status == StatusClosed {
+		return ErrAccountClosed
+	}
+	if !a.Balance.IsZero() {
+		return ErrAccountNotEmpty
+	}
 
-	return NewTransaction(a.ID, Withdrawal, amount)
+	a.Status = StatusClosed
+	return nil
 }
 
-func (a *Account) Transfer(to *Account, amount float64) (Transaction, Transaction, error) {
+func (a *Account) Deposit(amount Money, currency Currency) (Transaction, error) {
+	if err := a.canReceive(); err != nil {
+		return Transaction{}, err
+	}
+	amount, err := a.validateAmount(amount, currency)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	balance, err := a.Balance.Add(amount)
+	if err != nil {
+		return Transaction{}, err
+	}
+	a.Balance = balance
+
+	return NewTransaction(a.ID, Deposit, amount, currency)
+}
+
+func (a *Account) Withdraw(amount Money, currency Currency) (Transaction, error) {
+	if err := a.canSend(); err != nil {
+		return Transaction{}, err
+	}
+	amount, err := a.validateAmount(amount, currency)
+	if err != nil {
+		return Transaction{}, err
+	}
+	if err := a.covers(amount); err != nil {
+		return Transaction{}, err
+	}
+
+	balance, err := a.Balance.Sub(amount)
+	if err != nil {
+		return Transaction{}, err
+	}
+	a.Balance = balance
+
+	return NewTransaction(a.ID, Withdrawal, amount, currency)
+}
+
+func (a *Account) Transfer(to *Account, amount Money, currency Currency) (Transfer, error) {
+	if err := a.canTransferTo(to); err != nil {
+		return Transfer{}, err
+	}
+	amount, err := a.validateAmount(amount, currency)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if to.Currency != a.Currency {
+		return Transfer{}, ErrCurrencyMismatch
+	}
+
+	return a.transfer(to, amount, amount, nil)
+}
+
+// TransferWithConversion moves amount (in the source account's currency)
+// to an account held in another currency, converting it at rate. Both
+// legs record the conversion that was applied.
+func (a *Account) TransferWithConversion(to *Account, amount Money, currency Currency, rate ExchangeRate) (Transfer, error) {
+	if err := a.canTransferTo(to); err != nil {
+		return Transfer{}, err
+	}
+	amount, err := a.validateAmount(amount, currency)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if rate.From != a.Currency || rate.To != to.Currency {
+		return Transfer{}, ErrInvalidExchangeRate
+	}
+
+	converted, err := rate.Convert(amount)
+	if err != nil {
+		return Transfer{}, err
+	}
+	if !converted.IsPositive() {
+		return Transfer{}, ErrInvalidAmount
+	}
+
+	return a.transfer(to, amount, converted, &Conversion{
+		OriginalAmount:    amount,
+		OriginalCurrency:  a.Currency,
+		ConvertedAmount:   converted,
+		ConvertedCurrency: to.Currency,
+		Rate:              rate.Rate,
+		RateTimestamp:     rate.Timestamp,
+	})
+}
+
+// transfer debits a and credits to, producing a Transfer with its withdrawal
+// and deposit legs. debit and credit differ only when the amount has been
+// converted.
+func (a *Account) transfer(to *Account, debit, credit Money, conversion *Conversion) (Transfer, error) {
+	if err := a.covers(debit); err != nil {
+		return Transfer{}, err
+	}
+
+	fromBalance, err := a.Balance.Sub(debit)
+	if err != nil {
+		return Transfer{}, err
+	}
+	toBalance, err := to.Balance.Add(credit)
+	if err != nil {
+		return Transfer{}, err
+	}
+	a.Balance = fromBalance
+	to.Balance = toBalance
+
+	transferID := GetUUID()
+	timestamp := GetTimeNow()
+
+	return Transfer{
+		ID: transferID,
+		Withdrawal: Transaction{
+			ID:             GetUUID(),
+			AccountID:      a.ID,
+			Type:           Withdrawal,
+			Amount:         debit,
+			Currency:       a.Currency,
+			Conversion:     conversion,
+			TransferID:     transferID,
+			CounterpartyID: to.ID,
+			Timestamp:      timestamp,
+		},
+		Deposit: Transaction{
+			ID:             GetUUID(),
+			AccountID:      to.ID,
+			Type:           Deposit,
+			Amount:         credit,
+			Currency:       to.Currency,
+			Conversion:     conversion,
+			TransferID:     transferID,
+			CounterpartyID: a.ID,
+			Timestamp:      timestamp,
+		},
+		Timestamp: timestamp,
+	}, nil
+}
+
+// covers checks that amount can be taken from the account without the
+// balance dropping below its overdraft limit.
+func (a *Account) covers(amount Money) error {
+	available, err := a.Balance.Add(a.OverdraftLimit)
+	if err != nil {
+		return err
+	}
+	if amount.Cmp(available) > 0 {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// canTransferTo checks that a may send funds and to may receive them.
+func (a *Account) canTransferTo(to *Account) error {
 	if a.ID == to.ID {
-		return Transaction{}, Transaction{}, ErrSelfTransfer
-	}
-	if amount <= 0 {
-		return Transaction{}, Transaction{}, ErrInvalidAmount
-	}
-	if amount > a.Balance {
-		return Transaction{}, Transaction{}, ErrInsufficientFunds
-	}
-
-	a.Balance -= amount
-	to.Balance += amount
-
-	return Transaction{
-			ID:        GetUUID(),
-			AccountID: a.ID,
-			Type:      Withdrawal,
-			Amount:    amount,
-			Timestamp: GetTimeNow(),
-		}, Transaction{
-			ID:        GetUUID(),
-			AccountID: to.ID,
-			Type:      Deposit,
-			Amount:    amount,
-			Timestamp: GetTimeNow(),
-		}, nil
+		return ErrSelfTransfer
+	}
+	if err := a.canSend(); err != nil {
+		return err
+	}
+	return to.canReceive()
+}
+
+// canSend reports whether funds may leave the account.
+func (a *Account) canSend() error {
+	switch a.Status {
+	case StatusFrozen:
+		return ErrAccountFrozen
+	case StatusClosed:
+		return ErrAccountClosed
+	default:
+		return nil
+	}
+}
+
+// canReceive reports whether funds may enter the account.
+func (a *Account) canReceive() error {
+	if a.Status == StatusClosed {
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// validateAmount checks that amount is a positive value in the account's
+// currency and expresses it with the currency's number of minor units.
+func (a *Account) validateAmount(amount Money, currency Currency) (Money, error) {
+	if err := currency.Validate(); err != nil {
+		return Money{}, err
+	}
+	if currency != a.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return validateAmount(amount, currency)
+}
+
+// validateAmount checks that amount is positive and expresses it with the
+// number of minor units defined for currency.
+func validateAmount(amount Money, currency Currency) (Money, error) {
+	if !amount.IsPositive() {
+		return Money{}, ErrInvalidAmount
+	}
+	return currency.normalize(amount)
 }
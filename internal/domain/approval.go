@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"time"
+)
+
+// ApprovalStatus is the state of a TransferApproval.
+type ApprovalStatus string
+
+const (
+	// ApprovalPending transfers wait for an operator's decision.
+	ApprovalPending ApprovalStatus = "pending"
+	// ApprovalApproved transfers were approved and made.
+	ApprovalApproved ApprovalStatus = "approved"
+	// ApprovalRejected transfers were rejected and never made.
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// Validate returns ErrInvalidApprovalStatus unless s is a known status.
+func (s ApprovalStatus) Validate() error {
+	switch s {
+	case ApprovalPending, ApprovalApproved, ApprovalRejected:
+		return nil
+	default:
+		return ErrInvalidApprovalStatus
+	}
+}
+
+// ApprovalAction identifies a step in the history of a TransferApproval.
+type ApprovalAction string
+
+const (
+	ApprovalRequested ApprovalAction = "requested"
+	ApprovalGranted   ApprovalAction = "approved"
+	ApprovalDenied    ApprovalAction = "rejected"
+)
+
+// ApprovalEvent records who took a step in the history of a
+// TransferApproval, and when.
+type ApprovalEvent struct {
+	Action    ApprovalAction `json:"action"`
+	Actor     string         `json:"actor"`
+	Reason    string         `json:"reason,omitempty"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// ApprovalThreshold is the amount, in Currency, above which a transfer
+// needs approval. Transfers in other currencies are converted to Currency
+// before they are compared. A zero Amount disables approvals.
+type ApprovalThreshold struct {
+	Amount   Money
+	Currency Currency
+}
+
+// Enabled reports whether any transfer needs approval.
+func (t ApprovalThreshold) Enabled() bool {
+	return t.Amount.IsPositive()
+}
+
+// TransferApproval is a transfer that needs the approval of an operator
+// other than the one who requested it (maker-checker) before it is made.
+type TransferApproval struct {
+	ID            string         `json:"id"`
+	FromAccountID string         `json:"from_account_id"`
+	ToAccountID   string         `json:"to_account_id"`
+	Amount        Money          `json:"amount"`
+	Currency      Currency       `json:"currency"`
+	Status        ApprovalStatus `json:"status"`
+	// RequestedBy is the subject of the principal that requested the
+	// transfer.
+	RequestedBy string    `json:"requested_by"`
+	RequestedAt time.Time `json:"requested_at"`
+	// TransferID is the transfer made once approved.
+	TransferID string          `json:"transfer_id,omitempty"`
+	History    []ApprovalEvent `json:"history"`
+	Version    int64           `json:"version"`
+}
+
+// NewTransferApproval returns a pending approval for a transfer requested
+// by requester.
+func NewTransferApproval(requester, fromAccountID, toAccountID string, amount Money, currency Currency) TransferApproval {
+	now := GetTimeNow()
+	return TransferApproval{
+		ID:            GetUUID(),
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		Currency:      currency,
+		Status:        ApprovalPending,
+		RequestedBy:   requester,
+		RequestedAt:   now,
+		History:       []ApprovalEvent{{Action: ApprovalRequested, Actor: requester, Timestamp: now}},
+	}
+}
+
+// Approve records approver's approval of the transfer made as transferID.
+func (a *TransferApproval) Approve(approver, transferID string) error {
+	if err := a.CheckDecision(approver); err != nil {
+		return err
+	}
+
+	a.Status = ApprovalApproved
+	a.TransferID = transferID
+	a.History = append(a.History, ApprovalEvent{Action: ApprovalGranted, Actor: approver, Timestamp: GetTimeNow()})
+	return nil
+}
+
+// Reject records approver's rejection of the transfer, for reason.
+func (a *TransferApproval) Reject(approver, reason string) error {
+	if err := a.CheckDecision(approver); err != nil {
+		return err
+	}
+
+	a.Status = ApprovalRejected
+	a.History = append(a.History, ApprovalEvent{Action: ApprovalDenied, Actor: approver, Reason: reason, Timestamp: GetTimeNow()})
+	return nil
+}
+
+// CheckDecision returns the error Approve or Reject would fail with if
+// approver decided on a: only a pending transfer can be decided on, and
+// not by its requester.
+func (a TransferApproval) CheckDecision(approver string) error {
+	switch {
+	case a.Status != ApprovalPending:
+		return ErrApprovalNotPending
+	case approver == a.RequestedBy:
+		return ErrSelfApproval
+	default:
+		return nil
+	}
+}
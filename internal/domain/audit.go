@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+)
+
+// AuditAction identifies the kind of change recorded in an AuditEvent.
+type AuditAction string
+
+const (
+	AuditOverdraftLimitChanged AuditAction = "overdraft_limit_changed"
+)
+
+// AuditEvent records a change to an account setting, with the value it had
+// before and after the change.
+type AuditEvent struct {
+	ID        string      `json:"id"`
+	AccountID string      `json:"account_id"`
+	Action    AuditAction `json:"action"`
+	OldValue  string      `json:"old_value"`
+	NewValue  string      `json:"new_value"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+func NewAuditEvent(accountID string, action AuditAction, oldValue, newValue string) AuditEvent {
+	return AuditEvent{
+		ID:        GetUUID(),
+		AccountID: accountID,
+		Action:    action,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		Timestamp: GetTimeNow(),
+	}
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"time"
+)
+
+// Role determines what a principal may do.
+type Role string
+
+const (
+	// RoleCustomer may only act on the accounts it owns or was granted.
+	RoleCustomer Role = "customer"
+	// RoleOperator may read and act on every account.
+	RoleOperator Role = "operator"
+	// RoleAdmin may do everything an operator may, and issue API keys.
+	RoleAdmin Role = "admin"
+)
+
+// Validate returns ErrInvalidRole unless r is a known role.
+func (r Role) Validate() error {
+	switch r {
+	case RoleCustomer, RoleOperator, RoleAdmin:
+		return nil
+	default:
+		return ErrInvalidRole
+	}
+}
+
+// Principal is the authenticated caller of an operation.
+type Principal struct {
+	// Subject identifies the caller. A customer owns the accounts whose
+	// Owner is its Subject.
+	Subject string `json:"subject"`
+	Role    Role   `json:"role"`
+	// AccountIDs are accounts a customer may use besides its own, as
+	// granted by the issuer of its token.
+	AccountIDs []string `json:"account_ids,omitempty"`
+}
+
+// IsOperator reports whether p may act on every account.
+func (p Principal) IsOperator() bool {
+	return p.Role == RoleOperator || p.Role == RoleAdmin
+}
+
+// CanAccess reports whether p may read and act on account.
+func (p Principal) CanAccess(account Account) bool {
+	return p.IsOperator() || account.Owner == p.Subject || slices.Contains(p.AccountIDs, account.ID)
+}
+
+// apiKeyPrefix starts every API key token, so that leaked keys are easy
+// to recognise.
+const apiKeyPrefix = "bk_"
+
+// APIKey is a credential issued to a principal. Only the SHA-256 hash of
+// its token is kept; the token itself is shown once, when it is issued.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Hash      string    `json:"hash"`
+	Principal Principal `json:"principal"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewAPIKey returns the key that authenticates token as the given subject
+// and role.
+func NewAPIKey(token, subject string, role Role) (APIKey, error) {
+	if token == "" {
+		return APIKey{}, ErrInvalidAPIKey
+	}
+	if subject == "" {
+		return APIKey{}, ErrInvalidSubject
+	}
+	if err := role.Validate(); err != nil {
+		return APIKey{}, err
+	}
+
+	return APIKey{
+		ID:        GetUUID(),
+		Hash:      HashAPIKeyToken(token),
+		Principal: Principal{Subject: subject, Role: role},
+		CreatedAt: GetTimeNow(),
+	}, nil
+}
+
+// GenerateAPIKeyToken returns a new random API key token.
+func GenerateAPIKeyToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// HashAPIKeyToken returns the hash under which the key for token is
+// stored. Tokens are random and long, so a fast hash is enough.
+func HashAPIKeyToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
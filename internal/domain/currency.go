@@ -0,0 +1,52 @@
+package domain
+
+import "strings"
+
+// Currency is an ISO 4217 alphabetic currency code, e.g. "USD".
+type Currency string
+
+// currencyMinorUnits maps every supported currency to the number of
+// fractional digits (minor units) defined for it by ISO 4217.
+var currencyMinorUnits = func() map[Currency]int {
+	byMinorUnits := map[int]string{
+		0: "BIF CLP DJF GNF ISK JPY KMF KRW PYG RWF UGX VND VUV XAF XOF XPF",
+		2: "AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BMD BND BOB BRL BSD BTN " +
+			"BWP BYN BZD CAD CDF CHF CNY COP CRC CUP CVE CZK DKK DOP DZD EGP ERN ETB EUR FJD " +
+			"FKP GBP GEL GHS GIP GMD GTQ GYD HKD HNL HTG HUF IDR ILS INR IRR JMD KES KGS KHR " +
+			"KPW KYD KZT LAK LBP LKR LRD LSL MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN " +
+			"MYR MZN NAD NGN NIO NOK NPR NZD PAB PEN PGK PHP PKR PLN QAR RON RSD RUB SAR SBD " +
+			"SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TOP TRY TTD TWD " +
+			"TZS UAH USD UYU UZS VES WST XCD YER ZAR ZMW ZWG",
+		3: "BHD IQD JOD KWD LYD OMR TND",
+	}
+
+	units := make(map[Currency]int)
+	for minorUnits, codes := range byMinorUnits {
+		for _, code := range strings.Fields(codes) {
+			units[Currency(code)] = minorUnits
+		}
+	}
+	return units
+}()
+
+// Validate returns ErrInvalidCurrency unless c is a supported ISO 4217 code.
+func (c Currency) Validate() error {
+	if _, ok := currencyMinorUnits[c]; !ok {
+		return ErrInvalidCurrency
+	}
+	return nil
+}
+
+// MinorUnits returns the number of fractional digits amounts in c may carry.
+func (c Currency) MinorUnits() int {
+	return currencyMinorUnits[c]
+}
+
+// normalize checks that amount is representable in c and returns it
+// expressed with exactly c.MinorUnits() fractional digits.
+func (c Currency) normalize(amount Money) (Money, error) {
+	if err := c.Validate(); err != nil {
+		return Money{}, err
+	}
+	return amount.Rescale(c.MinorUnits())
+}
@@ -6,12 +6,40 @@ import (
 
 var (
 	ErrAccountAlreadyExists       = errors.New("account already exists")
+	ErrAccountClosed              = errors.New("account is closed")
+	ErrAccountFrozen              = errors.New("account is frozen")
+	ErrAccountNotEmpty            = errors.New("account balance must be zero to close the account")
 	ErrAccountTransactionMismatch = errors.New("account and transaction mismatch")
+	ErrAmountOverflow             = errors.New("amount is out of range")
+	ErrAmountPrecision            = errors.New("amount has more decimal places than the currency allows")
+	ErrAPIKeyAlreadyExists        = errors.New("API key already exists")
+	ErrAPIKeyNotFound             = errors.New("API key not found")
+	ErrApprovalAlreadyExists      = errors.New("transfer approval already exists")
+	ErrApprovalNotFound           = errors.New("transfer approval not found")
+	ErrApprovalNotPending         = errors.New("transfer approval has already been decided")
+	ErrCurrencyMismatch           = errors.New("currency does not match account currency")
+	ErrExchangeRateUnavailable    = errors.New("no exchange rate available for currency pair")
+	ErrForbidden                  = errors.New("not allowed to perform this operation")
 	ErrInsufficientFunds          = errors.New("insufficient funds")
 	ErrInvalidAccountID           = errors.New("invalid account")
 	ErrInvalidAmount              = errors.New("transaction amount must be positive")
+	ErrInvalidAPIKey              = errors.New("API key cannot be empty")
+	ErrInvalidApprovalStatus      = errors.New("unknown approval status")
+	ErrInvalidCurrency            = errors.New("unsupported currency")
+	ErrInvalidCursor              = errors.New("invalid pagination cursor")
+	ErrInvalidExchangeRate        = errors.New("invalid exchange rate")
+	ErrInvalidMoney               = errors.New("invalid monetary amount")
+	ErrInvalidOverdraftLimit      = errors.New("overdraft limit cannot be negative")
 	ErrInvalidOwner               = errors.New("owner name cannot be empty")
+	ErrInvalidRole                = errors.New("unknown role")
+	ErrInvalidStatusTransition    = errors.New("account status does not allow this change")
+	ErrInvalidSubject             = errors.New("subject cannot be empty")
 	ErrInvalidTransactionType     = errors.New("invalid transaction type")
 	ErrNegativeBalance            = errors.New("initial balance cannot be negative")
+	ErrOverdraftInUse             = errors.New("balance is below the requested overdraft limit")
+	ErrSelfApproval               = errors.New("a transfer cannot be approved or rejected by its requester")
 	ErrSelfTransfer               = errors.New("cannot transfer funds to the same account")
+	ErrTransferNotFound           = errors.New("transfer not found")
+	ErrUnauthenticated            = errors.New("missing or invalid credentials")
+	ErrVersionConflict            = errors.New("account was modified concurrently")
 )
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+)
+
+// Rate is an exact decimal exchange rate: one unit of the source currency
+// is worth Rate units of the target currency.
+type Rate Money
+
+// ParseRate parses a positive decimal exchange rate such as "0.9215".
+func ParseRate(s string) (Rate, error) {
+	m, err := ParseMoney(s)
+	if err != nil {
+		return Rate{}, err
+	}
+	if !m.IsPositive() {
+		return Rate{}, ErrInvalidExchangeRate
+	}
+	return Rate(m), nil
+}
+
+// MustParseRate is like ParseRate but panics if s is not a valid rate.
+// It is intended for constants and tests.
+func MustParseRate(s string) Rate {
+	r, err := ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r Rate) String() string               { return Money(r).String() }
+func (r Rate) Equal(o Rate) bool            { return Money(r).Equal(Money(o)) }
+func (r Rate) IsPositive() bool             { return Money(r).IsPositive() }
+func (r Rate) MarshalText() ([]byte, error) { return Money(r).MarshalText() }
+
+func (r *Rate) UnmarshalText(text []byte) error {
+	parsed, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
+
+// ExchangeRate is a quote for converting From into To, as of Timestamp.
+type ExchangeRate struct {
+	From      Currency  `json:"from"`
+	To        Currency  `json:"to"`
+	Rate      Rate      `json:"rate"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func NewExchangeRate(from, to Currency, rate Rate, timestamp time.Time) (ExchangeRate, error) {
+	if err := from.Validate(); err != nil {
+		return ExchangeRate{}, err
+	}
+	if err := to.Validate(); err != nil {
+		return ExchangeRate{}, err
+	}
+	if !rate.IsPositive() {
+		return ExchangeRate{}, ErrInvalidExchangeRate
+	}
+
+	return ExchangeRate{From: from, To: to, Rate: rate, Timestamp: timestamp}, nil
+}
+
+// Convert multiplies amount (in From) by the rate and rounds the result
+// half-to-even to the number of minor units of To.
+func (r ExchangeRate) Convert(amount Money) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(amount.units), big.NewInt(r.Rate.units))
+	scale := amount.scale + r.Rate.scale
+	target := r.To.MinorUnits()
+
+	if scale > target {
+		divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-target)), nil)
+		quotient, remainder := new(big.Int).QuoRem(product, divisor, new(big.Int))
+
+		// QuoRem truncates towards zero; step away from zero when the
+		// remainder is above one half, or exactly one half and odd.
+		twice := new(big.Int).Abs(remainder)
+		twice.Lsh(twice, 1)
+		if c := twice.Cmp(divisor); c > 0 || (c == 0 && quotient.Bit(0) == 1) {
+			quotient.Add(quotient, big.NewInt(int64(product.Sign())))
+		}
+		product = quotient
+	} else {
+		multiplier := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(target-scale)), nil)
+		product.Mul(product, multiplier)
+	}
+
+	if !product.IsInt64() {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{units: product.Int64(), scale: target}, nil
+}
+
+// Conversion records how a transfer amount was converted between
+// currencies, so that both legs of the transfer can be audited.
+type Conversion struct {
+	OriginalAmount    Money     `json:"original_amount"`
+	OriginalCurrency  Currency  `json:"original_currency"`
+	ConvertedAmount   Money     `json:"converted_amount"`
+	ConvertedCurrency Currency  `json:"converted_currency"`
+	Rate              Rate      `json:"rate"`
+	RateTimestamp     time.Time `json:"rate_timestamp"`
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Internal system accounts that act as the contra side of customer postings.
+const (
+	// LedgerCash is money entering or leaving the bank (deposits, withdrawals
+	// and opening balances).
+	LedgerCash = "system:cash"
+	// LedgerSuspense holds funds in flight between the legs of a transfer,
+	// including the currency positions of converted transfers.
+	LedgerSuspense = "system:suspense"
+	// LedgerFees is reserved for fee income. No operation charges fees yet,
+	// so nothing is posted to it.
+	LedgerFees = "system:fees"
+)
+
+// EntryDirection is the side of the ledger an entry is posted to.
+type EntryDirection string
+
+const (
+	Debit  EntryDirection = "debit"
+	Credit EntryDirection = "credit"
+)
+
+// LedgerEntry is one side of a double-entry posting. Account is either a
+// customer account ID or one of the system ledger accounts. Reference is
+// the ID of the transaction (or, for an opening balance, the account) that
+// caused the posting.
+type LedgerEntry struct {
+	ID        string         `json:"id"`
+	Reference string         `json:"reference"`
+	Account   string         `json:"account"`
+	Direction EntryDirection `json:"direction"`
+	Amount    Money          `json:"amount"`
+	Currency  Currency       `json:"currency"`
+	Timestamp time.Time      `json:"timestamp"`
+}
+
+// OpeningEntries posts an account's initial balance against LedgerCash.
+// It returns no entries for an account opened with a zero balance.
+func OpeningEntries(account Account) []LedgerEntry {
+	if account.Balance.IsZero() {
+		return nil
+	}
+	return posting(account.ID, LedgerCash, account.ID, account.Balance, account.Currency, GetTimeNow())
+}
+
+// PostTransaction returns the balanced pair of entries for txn, posted
+// against the given contra account: LedgerCash for deposits and
+// withdrawals, LedgerSuspense for the legs of a transfer.
+//
+// Customer balances are liabilities of the bank, so a deposit credits the
+// customer account and a withdrawal debits it.
+func PostTransaction(txn Transaction, contra string) []LedgerEntry {
+	if txn.Type == Withdrawal {
+		return posting(txn.ID, txn.AccountID, contra, txn.Amount, txn.Currency, txn.Timestamp)
+	}
+	return posting(txn.ID, contra, txn.AccountID, txn.Amount, txn.Currency, txn.Timestamp)
+}
+
+func posting(reference, debit, credit string, amount Money, currency Currency, timestamp time.Time) []LedgerEntry {
+	return []LedgerEntry{
+		{
+			ID:        GetUUID(),
+			Reference: reference,
+			Account:   debit,
+			Direction: Debit,
+			Amount:    amount,
+			Currency:  currency,
+			Timestamp: timestamp,
+		},
+		{
+			ID:        GetUUID(),
+			Reference: reference,
+			Account:   credit,
+			Direction: Credit,
+			Amount:    amount,
+			Currency:  currency,
+			Timestamp: timestamp,
+		},
+	}
+}
+
+// LedgerCheck is the outcome of verifying the ledger invariants.
+type LedgerCheck struct {
+	// Balanced is true when every currency nets to zero and every customer
+	// account balance agrees with its ledger entries.
+	Balanced bool `json:"balanced"`
+	// EntryCount is the number of entries that were checked.
+	EntryCount int `json:"entry_count"`
+	// Totals holds debits minus credits per currency; all zero when balanced.
+	Totals map[Currency]Money `json:"totals"`
+	// Mismatches lists customer accounts whose balance differs from the
+	// balance implied by their entries.
+	Mismatches []BalanceMismatch `json:"mismatches,omitempty"`
+}
+
+// BalanceMismatch describes a customer account that disagrees with the ledger.
+type BalanceMismatch struct {
+	AccountID     string `json:"account_id"`
+	Balance       Money  `json:"balance"`
+	LedgerBalance Money  `json:"ledger_balance"`
+}
+
+// CheckLedger verifies that the sum of all entries is zero in every
+// currency (debits positive, credits negative) and that each account's
+// balance equals its credits minus its debits.
+func CheckLedger(accounts []Account, entries []LedgerEntry) (LedgerCheck, error) {
+	check := LedgerCheck{
+		EntryCount: len(entries),
+		Totals:     make(map[Currency]Money),
+	}
+
+	ledgerBalances := make(map[string]Money)
+	for _, entry := range entries {
+		signed := entry.Amount
+		if entry.Direction == Credit {
+			signed = signed.Neg()
+		}
+
+		total, err := check.Totals[entry.Currency].Add(signed)
+		if err != nil {
+			return LedgerCheck{}, err
+		}
+		check.Totals[entry.Currency] = total
+
+		balance, err := ledgerBalances[entry.Account].Sub(signed)
+		if err != nil {
+			return LedgerCheck{}, err
+		}
+		ledgerBalances[entry.Account] = balance
+	}
+
+	for _, account := range accounts {
+		if ledgerBalance := ledgerBalances[account.ID]; !ledgerBalance.Equal(account.Balance) {
+			check.Mismatches = append(check.Mismatches, BalanceMismatch{
+				AccountID:     account.ID,
+				Balance:       account.Balance,
+				LedgerBalance: ledgerBalance,
+			})
+		}
+	}
+	slices.SortFunc(check.Mismatches, func(a, b BalanceMismatch) int {
+		return strings.Compare(a.AccountID, b.AccountID)
+	})
+
+	check.Balanced = len(check.Mismatches) == 0
+	for _, total := range check.Totals {
+		if !total.IsZero() {
+			check.Balanced = false
+		}
+	}
+
+	return check, nil
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// maxScale is the largest number of fractional digits a Money value may carry.
+const maxScale = 18
+
+// Money is an exact decimal amount, stored as an integer number of minor
+// units together with its scale (the number of fractional digits).
+// The zero value is a valid amount of zero.
+type Money struct {
+	units int64
+	scale int
+}
+
+// NewMoney returns an amount of units minor units at the given scale,
+// e.g. NewMoney(1050, 2) is 10.50.
+func NewMoney(units int64, scale int) Money {
+	if scale < 0 || scale > maxScale {
+		panic(fmt.Sprintf("domain: money scale %d out of range", scale))
+	}
+	return Money{units: units, scale: scale}
+}
+
+// ParseMoney parses a plain decimal string such as "10", "-3.5" or "0.01".
+// The scale of the result is the number of fractional digits in s.
+func ParseMoney(s string) (Money, error) {
+	digits, negative := strings.CutPrefix(s, "-")
+	whole, frac, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && frac == "") || len(frac) > maxScale {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+
+	var units int64
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+		}
+		if units > (math.MaxInt64-int64(r-'0'))/10 {
+			return Money{}, fmt.Errorf("%w: %q", ErrAmountOverflow, s)
+		}
+		units = units*10 + int64(r-'0')
+	}
+	if negative {
+		units = -units
+	}
+
+	return Money{units: units, scale: len(frac)}, nil
+}
+
+// MustParseMoney is like ParseMoney but panics if s is not a valid amount.
+// It is intended for constants and tests.
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Units returns the amount in minor units at the current scale.
+func (m Money) Units() int64 { return m.units }
+
+// Scale returns the number of fractional digits of the amount.
+func (m Money) Scale() int { return m.scale }
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount.
+func (m Money) Sign() int {
+	switch {
+	case m.units < 0:
+		return -1
+	case m.units > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m Money) IsZero() bool     { return m.units == 0 }
+func (m Money) IsPositive() bool { return m.units > 0 }
+func (m Money) IsNegative() bool { return m.units < 0 }
+
+// Neg returns the amount with its sign inverted.
+func (m Money) Neg() Money {
+	return Money{units: -m.units, scale: m.scale}
+}
+
+// Rescale returns the same amount expressed with the given number of
+// fractional digits. It fails with ErrAmountPrecision if that would drop
+// non-zero digits, and with ErrAmountOverflow if the result does not fit.
+func (m Money) Rescale(scale int) (Money, error) {
+	if scale < 0 || scale > maxScale {
+		return Money{}, ErrAmountPrecision
+	}
+
+	units := m.units
+	for s := m.scale; s > scale; s-- {
+		if units%10 != 0 {
+			return Money{}, ErrAmountPrecision
+		}
+		units /= 10
+	}
+	for s := m.scale; s < scale; s++ {
+		if units > math.MaxInt64/10 || units < math.MinInt64/10 {
+			return Money{}, ErrAmountOverflow
+		}
+		units *= 10
+	}
+
+	return Money{units: units, scale: scale}, nil
+}
+
+// Add returns m+o at the larger of the two scales.
+func (m Money) Add(o Money) (Money, error) {
+	a, b, err := align(m, o)
+	if err != nil {
+		return Money{}, err
+	}
+
+	sum := a.units + b.units
+	if (b.units > 0 && sum < a.units) || (b.units < 0 && sum > a.units) {
+		return Money{}, ErrAmountOverflow
+	}
+
+	return Money{units: sum, scale: a.scale}, nil
+}
+
+// Sub returns m-o at the larger of the two scales.
+func (m Money) Sub(o Money) (Money, error) {
+	if o.units == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(o.Neg())
+}
+
+// Cmp compares m and o numerically and returns -1, 0 or +1.
+func (m Money) Cmp(o Money) int {
+	return m.rat().Cmp(o.rat())
+}
+
+// Equal reports whether m and o represent the same amount, regardless of scale.
+func (m Money) Equal(o Money) bool {
+	return m.Cmp(o) == 0
+}
+
+// String formats the amount as a plain decimal with exactly Scale()
+// fractional digits, e.g. "10.50" or "-0.01".
+func (m Money) String() string {
+	abs := new(big.Int).Abs(big.NewInt(m.units)).String()
+	if m.scale > 0 {
+		if len(abs) <= m.scale {
+			abs = strings.Repeat("0", m.scale-len(abs)+1) + abs
+		}
+		abs = abs[:len(abs)-m.scale] + "." + abs[len(abs)-m.scale:]
+	}
+
+	if m.units < 0 {
+		return "-" + abs
+	}
+	return abs
+}
+
+// MarshalText encodes the amount as a decimal string, so that it is
+// serialized as a JSON string rather than a lossy JSON number.
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes a decimal string produced by MarshalText.
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+func (m Money) rat() *big.Rat {
+	denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(m.scale)), nil)
+	return new(big.Rat).SetFrac(big.NewInt(m.units), denom)
+}
+
+// align rescales a and b to a common scale.
+func align(a, b Money) (Money, Money, error) {
+	var err error
+	switch {
+	case a.scale < b.scale:
+		a, err = a.Rescale(b.scale)
+	case b.scale < a.scale:
+		b, err = b.Rescale(a.scale)
+	}
+	return a, b, err
+}
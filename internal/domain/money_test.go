@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr error
+	}{
+		{input: "10", want: NewMoney(10, 0)},
+		{input: "10.50", want: NewMoney(1050, 2)},
+		{input: "-0.01", want: NewMoney(-1, 2)},
+		{input: "0.000000000000000001", want: NewMoney(1, 18)},
+		{input: "", wantErr: ErrInvalidMoney},
+		{input: ".5", wantErr: ErrInvalidMoney},
+		{input: "5.", wantErr: ErrInvalidMoney},
+		{input: "1e3", wantErr: ErrInvalidMoney},
+		{input: "+1", wantErr: ErrInvalidMoney},
+		{input: "99999999999999999999", wantErr: ErrAmountOverflow},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseMoney(tc.input)
+			if tc.wantErr != nil {
+				assert.Assert(t, errors.Is(err, tc.wantErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, got, tc.want)
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, NewMoney(1050, 2).String(), "10.50")
+	assert.Equal(t, NewMoney(-5, 3).String(), "-0.005")
+	assert.Equal(t, NewMoney(7, 0).String(), "7")
+	assert.Equal(t, Money{}.String(), "0")
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	// Given: Amounts that cannot be represented exactly as float64
+	a := MustParseMoney("0.10")
+	b := MustParseMoney("0.2")
+
+	// When: Adding them
+	sum, err := a.Add(b)
+	assert.NilError(t, err)
+
+	// Then: The result is exact and uses the larger scale
+	assert.Equal(t, sum, NewMoney(30, 2))
+
+	diff, err := sum.Sub(MustParseMoney("0.3"))
+	assert.NilError(t, err)
+	assert.Assert(t, diff.IsZero())
+}
+
+func TestMoney_Rescale(t *testing.T) {
+	m, err := MustParseMoney("12.300").Rescale(2)
+	assert.NilError(t, err)
+	assert.Equal(t, m, NewMoney(1230, 2))
+
+	_, err = MustParseMoney("12.345").Rescale(2)
+	assert.Assert(t, errors.Is(err, ErrAmountPrecision))
+}
+
+func TestMoney_JSON(t *testing.T) {
+	// Given: A struct containing an amount
+	type payload struct {
+		Amount Money `json:"amount"`
+	}
+
+	// When: It is marshaled
+	data, err := json.Marshal(payload{Amount: NewMoney(1050, 2)})
+	assert.NilError(t, err)
+
+	// Then: The amount is encoded as a string
+	assert.Equal(t, string(data), `{"amount":"10.50"}`)
+
+	// And: JSON numbers are rejected on decode
+	var p payload
+	assert.Assert(t, json.Unmarshal([]byte(`{"amount":10.5}`), &p) != nil)
+	assert.NilError(t, json.Unmarshal([]byte(`{"amount":"10.5"}`), &p))
+	assert.Equal(t, p.Amount, NewMoney(105, 1))
+}
+
+func TestExchangeRate_Convert(t *testing.T) {
+	rate, err := NewExchangeRate("USD", "JPY", MustParseRate("150.5"), GetTimeNow())
+	assert.NilError(t, err)
+
+	tests := []struct {
+		amount string
+		want   string
+	}{
+		{amount: "1.00", want: "150"},   // 150.5 rounds half to even
+		{amount: "3.00", want: "452"},   // 451.5 rounds half to even
+		{amount: "0.01", want: "2"},     // 1.505 rounds up
+		{amount: "10.10", want: "1520"}, // 1520.05 rounds down
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.amount, func(t *testing.T) {
+			converted, err := rate.Convert(MustParseMoney(tc.amount))
+			assert.NilError(t, err)
+			assert.Equal(t, converted.String(), tc.want)
+		})
+	}
+}
@@ -13,15 +13,22 @@ const (
 )
 
 // Transaction represents a bank transaction entity.
+//
+// Transactions that are legs of a transfer carry the transfer's ID and the
+// account on the other side of it.
 type Transaction struct {
-	ID        string          `json:"id"`
-	AccountID string          `json:"account_id"`
-	Type      TransactionType `json:"type"`
-	Amount    float64         `json:"amount"`
-	Timestamp time.Time       `json:"timestamp"`
+	ID             string          `json:"id"`
+	AccountID      string          `json:"account_id"`
+	Type           TransactionType `json:"type"`
+	Amount         Money           `json:"amount"`
+	Currency       Currency        `json:"currency"`
+	Conversion     *Conversion     `json:"conversion,omitempty"`
+	TransferID     string          `json:"transfer_id,omitempty"`
+	CounterpartyID string          `json:"counterparty_account_id,omitempty"`
+	Timestamp      time.Time       `json:"timestamp"`
 }
 
-func NewTransaction(accountID string, txnType TransactionType, amount float64) (Transaction, error) {
+func NewTransaction(accountID string, txnType TransactionType, amount Money, currency Currency) (Transaction, error) {
 	if accountID == "" {
 		return Transaction{}, ErrInvalidAccountID
 	}
@@ -30,8 +37,9 @@ func NewTransaction(accountID string, txnType TransactionType, amount float64) (
 		return Transaction{}, ErrInvalidTransactionType
 	}
 
-	if amount <= 0 {
-		return Transaction{}, ErrInvalidAmount
+	amount, err := validateAmount(amount, currency)
+	if err != nil {
+		return Transaction{}, err
 	}
 
 	return Transaction{
@@ -39,6 +47,7 @@ func NewTransaction(accountID string, txnType TransactionType, amount float64) (
 		AccountID: accountID,
 		Type:      txnType,
 		Amount:    amount,
+		Currency:  currency,
 		Timestamp: GetTimeNow(),
 	}, nil
 }
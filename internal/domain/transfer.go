@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"time"
+)
+
+// Transfer links the withdrawal and deposit legs of a movement of funds
+// between two accounts. Both legs reference the transfer by its ID.
+type Transfer struct {
+	ID         string      `json:"id"`
+	Withdrawal Transaction `json:"withdrawal"`
+	Deposit    Transaction `json:"deposit"`
+	Timestamp  time.Time   `json:"timestamp"`
+}
+
+// FromAccountID returns the account the funds were taken from.
+func (t Transfer) FromAccountID() string { return t.Withdrawal.AccountID }
+
+// ToAccountID returns the account the funds were credited to.
+func (t Transfer) ToAccountID() string { return t.Deposit.AccountID }
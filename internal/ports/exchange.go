@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+// ExchangeRateProvider supplies exchange rates for cross-currency transfers.
+type ExchangeRateProvider interface {
+	// Rate returns the current rate for converting from into to, or
+	// domain.ErrExchangeRateUnavailable if the pair is not quoted.
+	Rate(ctx context.Context, from, to domain.Currency) (domain.ExchangeRate, error)
+}
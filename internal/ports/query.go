@@ -0,0 +1,98 @@
+package ports
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+const (
+	// DefaultPageSize is the page size used when a query does not set one.
+	DefaultPageSize = 50
+	// MaxPageSize is the largest page a query may request.
+	MaxPageSize = 500
+)
+
+// TransactionQuery selects a page of an account's transactions, ordered
+// from oldest to newest. Zero-valued filters match everything.
+type TransactionQuery struct {
+	AccountID string
+	// From and To bound the transaction timestamp; From is inclusive and
+	// To is exclusive.
+	From time.Time
+	To   time.Time
+	Type domain.TransactionType
+	// MinAmount and MaxAmount bound the amount, both inclusive.
+	MinAmount *domain.Money
+	MaxAmount *domain.Money
+	// Cursor continues from the NextCursor of a previous page of the same
+	// query; empty starts at the beginning.
+	Cursor string
+	Limit  int
+}
+
+// TransactionPage is one page of the result of a TransactionQuery.
+type TransactionPage struct {
+	Transactions []domain.Transaction
+	// NextCursor fetches the following page; it is empty on the last page.
+	NextCursor string
+}
+
+// Matches reports whether txn satisfies the query's filters. It ignores
+// AccountID, Cursor and Limit.
+func (q TransactionQuery) Matches(txn domain.Transaction) bool {
+	switch {
+	case !q.From.IsZero() && txn.Timestamp.Before(q.From),
+		!q.To.IsZero() && !txn.Timestamp.Before(q.To),
+		q.Type != "" && txn.Type != q.Type,
+		q.MinAmount != nil && txn.Amount.Cmp(*q.MinAmount) < 0,
+		q.MaxAmount != nil && txn.Amount.Cmp(*q.MaxAmount) > 0:
+		return false
+	default:
+		return true
+	}
+}
+
+// AccountQuery selects a page of accounts, ordered by ID. Zero-valued
+// filters match everything.
+type AccountQuery struct {
+	// VisibleTo, if set, matches only the accounts that principal may
+	// access.
+	VisibleTo *domain.Principal
+	// OwnerPrefix matches owners starting with it, ignoring case.
+	OwnerPrefix string
+	Status      domain.AccountStatus
+	// MinBalance and MaxBalance bound the balance, both inclusive.
+	MinBalance *domain.Money
+	MaxBalance *domain.Money
+	// Cursor continues from the NextCursor of a previous page of the same
+	// query; empty starts at the beginning.
+	Cursor string
+	Limit  int
+}
+
+// AccountPage is one page of the result of an AccountQuery.
+type AccountPage struct {
+	Accounts []domain.Account
+	// NextCursor fetches the following page; it is empty on the last page.
+	NextCursor string
+	// Total is the number of accounts matching the filters across all
+	// pages.
+	Total int
+}
+
+// Matches reports whether account satisfies the query's filters. It
+// ignores Cursor and Limit.
+func (q AccountQuery) Matches(account domain.Account) bool {
+	switch {
+	case q.VisibleTo != nil && !q.VisibleTo.CanAccess(account),
+		q.OwnerPrefix != "" && !strings.HasPrefix(strings.ToLower(account.Owner), strings.ToLower(q.OwnerPrefix)),
+		q.Status != "" && account.Status != q.Status,
+		q.MinBalance != nil && account.Balance.Cmp(*q.MinBalance) < 0,
+		q.MaxBalance != nil && account.Balance.Cmp(*q.MaxBalance) > 0:
+		return false
+	default:
+		return true
+	}
+}
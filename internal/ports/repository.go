@@ -7,13 +7,84 @@ import (
 )
 
 // Repository defines storage operations for accounts and transactions.
+// Every operation honours cancellation and deadlines of its context, and
+// fails with the context's error once it is done.
 type Repository interface {
 	// Account-related operations
 	CreateAccount(ctx context.Context, account domain.Account) error
 	GetAccount(ctx context.Context, accountID string) (domain.Account, error)
-	ListAccounts(ctx context.Context) []domain.Account
+	ListAccounts(ctx context.Context) ([]domain.Account, error)
+	// QueryAccounts returns the page of accounts selected by query.
+	// query.Limit must be positive. An unknown cursor fails with
+	// domain.ErrInvalidCursor.
+	QueryAccounts(ctx context.Context, query AccountQuery) (AccountPage, error)
 
 	// Transaction-related operations
 	Record(ctx context.Context, account domain.Account, txn domain.Transaction) error
-	ListTransactions(ctx context.Context, accountID string) []domain.Transaction
+	// ListTransactions fails with domain.ErrInvalidAccountID if the account
+	// does not exist.
+	ListTransactions(ctx context.Context, accountID string) ([]domain.Transaction, error)
+	// QueryTransactions returns the page of transactions selected by query.
+	// query.Limit must be positive. An unknown account fails with
+	// domain.ErrInvalidAccountID and an unknown cursor with
+	// domain.ErrInvalidCursor.
+	QueryTransactions(ctx context.Context, query TransactionQuery) (TransactionPage, error)
+	GetTransfer(ctx context.Context, transferID string) (domain.Transfer, error)
+
+	// Commit atomically applies every change in the changeset: either all
+	// account updates and transactions are stored, or none are. Each
+	// account's Version must match the stored version, otherwise
+	// domain.ErrVersionConflict is returned; stored versions are incremented.
+	Commit(ctx context.Context, changes Changeset) error
+
+	// Ledger-related operations
+	LedgerSnapshot(ctx context.Context) (LedgerSnapshot, error)
+
+	// Audit-related operations
+	// ListAuditEvents fails with domain.ErrInvalidAccountID if the account
+	// does not exist.
+	ListAuditEvents(ctx context.Context, accountID string) ([]domain.AuditEvent, error)
+
+	// Approval-related operations
+	// GetTransferApproval fails with domain.ErrApprovalNotFound if there
+	// is no approval with that ID.
+	GetTransferApproval(ctx context.Context, approvalID string) (domain.TransferApproval, error)
+	// ListTransferApprovals returns the approvals with the given status,
+	// or all of them if status is empty, oldest request first.
+	ListTransferApprovals(ctx context.Context, status domain.ApprovalStatus) ([]domain.TransferApproval, error)
+
+	// Credential-related operations
+	// GetAPIKey returns the key stored under hash, or fails with
+	// domain.ErrAPIKeyNotFound.
+	GetAPIKey(ctx context.Context, hash string) (domain.APIKey, error)
+}
+
+// Changeset groups updated accounts with the transactions that produced
+// them, so that they can be committed as a single unit of work.
+type Changeset struct {
+	// NewAccounts are created; none of them may exist yet.
+	NewAccounts []domain.Account
+	// Accounts are updated; all of them must exist.
+	Accounts []domain.Account
+	// Transactions must each belong to one of the new or updated accounts.
+	Transactions []domain.Transaction
+	// Transfers link pairs of the transactions above.
+	Transfers []domain.Transfer
+	// Entries are the double-entry ledger postings for the changes.
+	Entries []domain.LedgerEntry
+	// AuditEvents must each belong to one of the new or updated accounts.
+	AuditEvents []domain.AuditEvent
+	// APIKeys are created; no key with the same hash may exist yet.
+	APIKeys []domain.APIKey
+	// NewApprovals are created; none of them may exist yet.
+	NewApprovals []domain.TransferApproval
+	// Approvals are updated under the same version check as Accounts.
+	Approvals []domain.TransferApproval
+}
+
+// LedgerSnapshot is a consistent view of all accounts and ledger entries,
+// taken at a single point in time.
+type LedgerSnapshot struct {
+	Accounts []domain.Account
+	Entries  []domain.LedgerEntry
 }
@@ -8,10 +8,26 @@ import (
 
 // BankService defines business operations for accounts and transactions.
 type BankService interface {
-	CreateAccount(ctx context.Context, owner string, initialBalance float64) (string, error)
+	CreateAccount(ctx context.Context, owner string, currency domain.Currency, initialBalance, overdraftLimit domain.Money) (string, error)
 	GetAccount(ctx context.Context, accountID string) (domain.Account, error)
-	ListAccounts(ctx context.Context) []domain.Account
-	CreateTransaction(ctx context.Context, accountID string, txnType domain.TransactionType, amount float64) (domain.Transaction, error)
-	ListTransactions(ctx context.Context, accountID string) []domain.Transaction
-	Transfer(ctx context.Context, fromAccountID, toAccountID string, amount float64) (domain.Transaction, domain.Transaction, error)
+	ListAccounts(ctx context.Context) ([]domain.Account, error)
+	QueryAccounts(ctx context.Context, query AccountQuery) (AccountPage, error)
+	FreezeAccount(ctx context.Context, accountID string) (domain.Account, error)
+	UnfreezeAccount(ctx context.Context, accountID string) (domain.Account, error)
+	CloseAccount(ctx context.Context, accountID string) (domain.Account, error)
+	SetOverdraftLimit(ctx context.Context, accountID string, limit domain.Money) (domain.Account, error)
+	ListAuditEvents(ctx context.Context, accountID string) ([]domain.AuditEvent, error)
+	CreateTransaction(ctx context.Context, accountID string, txnType domain.TransactionType, amount domain.Money, currency domain.Currency) (domain.Transaction, error)
+	ListTransactions(ctx context.Context, accountID string) ([]domain.Transaction, error)
+	QueryTransactions(ctx context.Context, query TransactionQuery) (TransactionPage, error)
+	RequestTransfer(ctx context.Context, requester, fromAccountID, toAccountID string, amount domain.Money, currency domain.Currency) (domain.Transfer, domain.TransferApproval, error)
+	ApproveTransfer(ctx context.Context, approver, approvalID string) (domain.TransferApproval, error)
+	RejectTransfer(ctx context.Context, approver, approvalID, reason string) (domain.TransferApproval, error)
+	GetTransferApproval(ctx context.Context, approvalID string) (domain.TransferApproval, error)
+	ListTransferApprovals(ctx context.Context, status domain.ApprovalStatus) ([]domain.TransferApproval, error)
+	GetTransfer(ctx context.Context, transferID string) (domain.Transfer, error)
+	CheckLedger(ctx context.Context) (domain.LedgerCheck, error)
+	IssueAPIKey(ctx context.Context, subject string, role domain.Role) (string, domain.APIKey, error)
+	RegisterAPIKey(ctx context.Context, token, subject string, role domain.Role) (domain.APIKey, error)
+	Authenticate(ctx context.Context, token string) (domain.Principal, error)
 }
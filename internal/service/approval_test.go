@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/adapters/exchange"
+	"github.com/hesampakdaman/banking-service/internal/adapters/storage"
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+// testApprovalThreshold holds transfers above 1000 USD for approval.
+var testApprovalThreshold = domain.ApprovalThreshold{Amount: domain.MustParseMoney("1000"), Currency: "USD"}
+
+// approvalFixture initializes a BankService that holds transfers above
+// 1000 USD for approval, with two funded accounts.
+func approvalFixture(t *testing.T) (service *BankService, fromID, toID string) {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+	service = NewBankService(storage.NewMemoryRepository(), exchange.NewStaticRateProvider(testRates()...), testApprovalThreshold, logger)
+
+	ctx := context.Background()
+	fromID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("2000"), domain.Money{})
+	assert.NilError(t, err)
+	toID, err = service.CreateAccount(ctx, "Bob", "USD", domain.Money{}, domain.Money{})
+	assert.NilError(t, err)
+
+	return service, fromID, toID
+}
+
+func TestBankService_RequestTransfer(t *testing.T) {
+	service, fromID, toID := approvalFixture(t)
+	ctx := context.Background()
+
+	// When: Requesting a transfer above the threshold
+	transfer, approval, err := service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("1000.01"), "USD")
+	assert.NilError(t, err)
+
+	// Then: It should be held for approval
+	assert.Equal(t, transfer.ID, "")
+	assert.Equal(t, approval.Status, domain.ApprovalPending)
+	assert.Equal(t, approval.RequestedBy, "maker")
+
+	stored, err := service.GetTransferApproval(ctx, approval.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stored, approval)
+
+	// And: No money should have moved for it
+	fromAccount, err := service.GetAccount(ctx, fromID)
+	assert.NilError(t, err)
+	assert.Equal(t, fromAccount.Balance, domain.MustParseMoney("2000.00"))
+
+	// When: Requesting a transfer of the threshold
+	transfer, approval, err = service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("1000"), "USD")
+	assert.NilError(t, err)
+
+	// Then: It should be made at once
+	assert.Assert(t, transfer.ID != "")
+	assert.Equal(t, approval.ID, "")
+}
+
+func TestBankService_RequestTransfer_RefusesFailingTransfer(t *testing.T) {
+	service, fromID, toID := approvalFixture(t)
+
+	// When: Requesting a transfer above the threshold and the balance
+	_, _, err := service.RequestTransfer(context.Background(), "maker", fromID, toID, domain.MustParseMoney("3000"), "USD")
+
+	// Then: It should not be held for approval
+	assert.Assert(t, errors.Is(err, domain.ErrInsufficientFunds))
+
+	approvals, err := service.ListTransferApprovals(context.Background(), "")
+	assert.NilError(t, err)
+	assert.Equal(t, len(approvals), 0)
+}
+
+func TestBankService_RequestTransfer_ConvertsToThresholdCurrency(t *testing.T) {
+	// Given: A threshold of 1000 USD, and two EUR accounts
+	eurToUSD, err := domain.NewExchangeRate("EUR", "USD", domain.MustParseRate("1.10"), domain.GetTimeNow())
+	assert.NilError(t, err)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	service := NewBankService(storage.NewMemoryRepository(), exchange.NewStaticRateProvider(eurToUSD), testApprovalThreshold, logger)
+
+	ctx := context.Background()
+	fromID, err := service.CreateAccount(ctx, "Carol", "EUR", domain.MustParseMoney("5000"), domain.Money{})
+	assert.NilError(t, err)
+	toID, err := service.CreateAccount(ctx, "Dave", "EUR", domain.Money{}, domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Requesting a transfer of 950 EUR, which is 1045 USD
+	transfer, approval, err := service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("950"), "EUR")
+	assert.NilError(t, err)
+
+	// Then: It should be held for approval
+	assert.Equal(t, transfer.ID, "")
+	assert.Equal(t, approval.Status, domain.ApprovalPending)
+
+	// When: Requesting a transfer of 900 EUR, which is 990 USD
+	transfer, approval, err = service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("900"), "EUR")
+	assert.NilError(t, err)
+
+	// Then: It should be made at once
+	assert.Assert(t, transfer.ID != "")
+	assert.Equal(t, approval.ID, "")
+}
+
+func TestBankService_RequestTransfer_RefusesUnconvertibleCurrency(t *testing.T) {
+	// Given: A threshold of 1000 USD, no EUR to USD rate, and two EUR
+	// accounts
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	service := NewBankService(storage.NewMemoryRepository(), exchange.NewStaticRateProvider(), testApprovalThreshold, logger)
+
+	ctx := context.Background()
+	fromID, err := service.CreateAccount(ctx, "Carol", "EUR", domain.MustParseMoney("5000"), domain.Money{})
+	assert.NilError(t, err)
+	toID, err := service.CreateAccount(ctx, "Dave", "EUR", domain.Money{}, domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Requesting a transfer in EUR
+	_, _, err = service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("10"), "EUR")
+
+	// Then: It should be refused, as it cannot be compared to the threshold
+	assert.Assert(t, errors.Is(err, domain.ErrExchangeRateUnavailable))
+
+	fromAccount, err := service.GetAccount(ctx, fromID)
+	assert.NilError(t, err)
+	assert.Equal(t, fromAccount.Balance, domain.MustParseMoney("5000.00"))
+}
+
+func TestBankService_ApproveTransfer(t *testing.T) {
+	service, fromID, toID := approvalFixture(t)
+	ctx := context.Background()
+
+	// Given: A transfer held for approval
+	_, approval, err := service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("1500"), "USD")
+	assert.NilError(t, err)
+
+	// When: Its requester approves it
+	_, err = service.ApproveTransfer(ctx, "maker", approval.ID)
+
+	// Then: The approval should be refused
+	assert.Assert(t, errors.Is(err, domain.ErrSelfApproval))
+
+	// When: A second operator approves it
+	approved, err := service.ApproveTransfer(ctx, "checker", approval.ID)
+	assert.NilError(t, err)
+
+	// Then: The transfer should be made
+	assert.Equal(t, approved.Status, domain.ApprovalApproved)
+	assert.Equal(t, approved.Version, approval.Version+1)
+
+	transfer, err := service.GetTransfer(ctx, approved.TransferID)
+	assert.NilError(t, err)
+	assert.Equal(t, transfer.Deposit.AccountID, toID)
+
+	toAccount, err := service.GetAccount(ctx, toID)
+	assert.NilError(t, err)
+	assert.Equal(t, toAccount.Balance, domain.MustParseMoney("1500.00"))
+
+	// And: The decision should be stored
+	stored, err := service.GetTransferApproval(ctx, approval.ID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stored, approved)
+
+	// And: It should not be decided on again
+	_, err = service.ApproveTransfer(ctx, "checker", approval.ID)
+	assert.Assert(t, errors.Is(err, domain.ErrApprovalNotPending))
+}
+
+func TestBankService_ApproveTransfer_StaysPendingOnFailure(t *testing.T) {
+	service, fromID, toID := approvalFixture(t)
+	ctx := context.Background()
+
+	// Given: Two transfers held for approval that the balance cannot both cover
+	_, first, err := service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("1500"), "USD")
+	assert.NilError(t, err)
+	_, second, err := service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("1500"), "USD")
+	assert.NilError(t, err)
+
+	_, err = service.ApproveTransfer(ctx, "checker", first.ID)
+	assert.NilError(t, err)
+
+	// When: Approving the second
+	_, err = service.ApproveTransfer(ctx, "checker", second.ID)
+
+	// Then: It should fail and stay pending
+	assert.Assert(t, errors.Is(err, domain.ErrInsufficientFunds))
+
+	pending, err := service.ListTransferApprovals(ctx, domain.ApprovalPending)
+	assert.NilError(t, err)
+	assert.Equal(t, len(pending), 1)
+	assert.Equal(t, pending[0].ID, second.ID)
+}
+
+func TestBankService_RejectTransfer(t *testing.T) {
+	service, fromID, toID := approvalFixture(t)
+	ctx := context.Background()
+
+	// Given: A transfer held for approval
+	_, approval, err := service.RequestTransfer(ctx, "maker", fromID, toID, domain.MustParseMoney("1500"), "USD")
+	assert.NilError(t, err)
+
+	// When: A second operator rejects it
+	rejected, err := service.RejectTransfer(ctx, "checker", approval.ID, "Unexpected beneficiary")
+	assert.NilError(t, err)
+
+	// Then: It should be rejected with the reason, and never made
+	assert.Equal(t, rejected.Status, domain.ApprovalRejected)
+	assert.Equal(t, rejected.TransferID, "")
+	assert.DeepEqual(t, rejected.History[1], domain.ApprovalEvent{
+		Action:    domain.ApprovalDenied,
+		Actor:     "checker",
+		Reason:    "Unexpected beneficiary",
+		Timestamp: domain.GetTimeNow(),
+	})
+
+	fromAccount, err := service.GetAccount(ctx, fromID)
+	assert.NilError(t, err)
+	assert.Equal(t, fromAccount.Balance, domain.MustParseMoney("2000.00"))
+
+	// And: It should not be approved afterwards
+	_, err = service.ApproveTransfer(ctx, "checker", approval.ID)
+	assert.Assert(t, errors.Is(err, domain.ErrApprovalNotPending))
+}
+
+func TestBankService_TransferApproval_Errors(t *testing.T) {
+	service, _, _ := approvalFixture(t)
+	ctx := context.Background()
+
+	_, err := service.ApproveTransfer(ctx, "checker", "unknown")
+	assert.Assert(t, errors.Is(err, domain.ErrApprovalNotFound))
+
+	_, err = service.RejectTransfer(ctx, "checker", "unknown", "")
+	assert.Assert(t, errors.Is(err, domain.ErrApprovalNotFound))
+
+	_, err = service.ListTransferApprovals(ctx, "done")
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidApprovalStatus))
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+// IssueAPIKey creates a key for subject acting in role. The returned token
+// is the only copy of the key's secret; it cannot be recovered later.
+func (s *BankService) IssueAPIKey(ctx context.Context, subject string, role domain.Role) (string, domain.APIKey, error) {
+	token, err := domain.GenerateAPIKeyToken()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to generate API key", "error", err.Error())
+		return "", domain.APIKey{}, err
+	}
+
+	key, err := s.RegisterAPIKey(ctx, token, subject, role)
+	if err != nil {
+		return "", domain.APIKey{}, err
+	}
+	return token, key, nil
+}
+
+// RegisterAPIKey creates a key that authenticates the given token, which
+// is chosen by the caller, such as a bootstrap key from configuration.
+func (s *BankService) RegisterAPIKey(ctx context.Context, token, subject string, role domain.Role) (domain.APIKey, error) {
+	logger := s.logger.With("subject", subject, "role", role)
+
+	logger.InfoContext(ctx, "Registering API key")
+
+	key, err := domain.NewAPIKey(token, subject, role)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to register API key", "reason", err.Error())
+		return domain.APIKey{}, err
+	}
+
+	logger = logger.With("api_key_id", key.ID)
+	if err := s.repo.Commit(ctx, ports.Changeset{APIKeys: []domain.APIKey{key}}); err != nil {
+		logger.ErrorContext(ctx, "Failed to register API key", "error", err.Error())
+		return domain.APIKey{}, err
+	}
+
+	logger.InfoContext(ctx, "Successfully registered API key")
+	return key, nil
+}
+
+// Authenticate returns the principal token was issued to, or fails with
+// domain.ErrUnauthenticated if no key matches it.
+func (s *BankService) Authenticate(ctx context.Context, token string) (domain.Principal, error) {
+	key, err := s.repo.GetAPIKey(ctx, domain.HashAPIKeyToken(token))
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		s.logger.WarnContext(ctx, "Rejected unknown API key")
+		return domain.Principal{}, domain.ErrUnauthenticated
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to look up API key", "error", err.Error())
+		return domain.Principal{}, err
+	}
+
+	return key.Principal, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+func TestBankService_IssueAndAuthenticate(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: A key issued to a customer
+	token, key, err := service.IssueAPIKey(ctx, "Alice", domain.RoleCustomer)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(token, "bk_"), "got %q", token)
+	assert.Assert(t, key.Hash != token, "the token should not be stored")
+
+	// When: Authenticating with its token
+	principal, err := service.Authenticate(ctx, token)
+
+	// Then: The customer should be identified
+	assert.NilError(t, err)
+	assert.DeepEqual(t, principal, domain.Principal{Subject: "Alice", Role: domain.RoleCustomer})
+}
+
+func TestBankService_AuthenticateUnknownToken(t *testing.T) {
+	service := fixture()
+
+	// When: Authenticating with a token that was never issued
+	_, err := service.Authenticate(context.Background(), "bk_unknown")
+
+	// Then: The caller should not be authenticated
+	assert.Assert(t, errors.Is(err, domain.ErrUnauthenticated), "got %v", err)
+}
+
+func TestBankService_RegisterAPIKey_Errors(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+	_, err := service.RegisterAPIKey(ctx, "registered", "Alice", domain.RoleCustomer)
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name     string
+		token    string
+		subject  string
+		role     domain.Role
+		expected error
+	}{
+		{name: "Empty token", token: "", subject: "Alice", role: domain.RoleCustomer, expected: domain.ErrInvalidAPIKey},
+		{name: "Empty subject", token: "token", subject: "", role: domain.RoleCustomer, expected: domain.ErrInvalidSubject},
+		{name: "Unknown role", token: "token", subject: "Alice", role: "superuser", expected: domain.ErrInvalidRole},
+		{name: "Token already registered", token: "registered", subject: "Bob", role: domain.RoleAdmin, expected: domain.ErrAPIKeyAlreadyExists},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Registering the key
+			_, err := service.RegisterAPIKey(ctx, tc.token, tc.subject, tc.role)
+
+			// Then: It should be rejected
+			assert.Assert(t, errors.Is(err, tc.expected), "got %v", err)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hesampakdaman/banking-service/internal/adapters/exchange"
 	"github.com/hesampakdaman/banking-service/internal/adapters/storage"
 	"github.com/hesampakdaman/banking-service/internal/domain"
 )
@@ -18,7 +19,13 @@ func fixture() *BankService {
 		Level: slog.LevelDebug,
 	}))
 
-	return NewBankService(repo, logger)
+	return NewBankService(repo, exchange.NewStaticRateProvider(testRates()...), domain.ApprovalThreshold{}, logger)
+}
+
+// testRates returns the exchange rates available to BankService in tests.
+func testRates() []domain.ExchangeRate {
+	usdToEUR, _ := domain.NewExchangeRate("USD", "EUR", domain.MustParseRate("0.9234"), domain.GetTimeNow())
+	return []domain.ExchangeRate{usdToEUR}
 }
 
 func TestMain(m *testing.M) {
@@ -2,23 +2,41 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 )
 
-func (s *BankService) CreateAccount(ctx context.Context, owner string, initialBalance float64) (string, error) {
-	logger := s.logger.With("owner", owner, "balance", initialBalance)
+func (s *BankService) CreateAccount(ctx context.Context, owner string, currency domain.Currency, initialBalance, overdraftLimit domain.Money) (string, error) {
+	logger := s.logger.With("owner", owner, "currency", currency, "balance", initialBalance, "overdraft_limit", overdraftLimit)
 
 	logger.InfoContext(ctx, "Creating account")
 
-	account, err := domain.NewAccount(domain.GetUUID(), owner, initialBalance)
+	account, err := domain.NewAccount(domain.GetUUID(), owner, currency, initialBalance)
 	if err != nil {
 		logger.WarnContext(ctx, "Failed to create account", "reason", err.Error())
 		return "", err
 	}
 
+	var auditEvents []domain.AuditEvent
+	if !overdraftLimit.IsZero() {
+		event, err := account.SetOverdraftLimit(overdraftLimit)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to create account", "reason", err.Error())
+			return "", err
+		}
+		auditEvents = append(auditEvents, event)
+	}
+
+	// Store the account together with the ledger posting for its opening balance
 	logger = logger.With("account_id", account.ID)
-	if err := s.repo.CreateAccount(ctx, account); err != nil {
+	changes := ports.Changeset{
+		NewAccounts: []domain.Account{account},
+		Entries:     domain.OpeningEntries(account),
+		AuditEvents: auditEvents,
+	}
+	if err := s.repo.Commit(ctx, changes); err != nil {
 		logger.ErrorContext(ctx, "Failed to create account", "error", err.Error())
 		return "", err
 	}
@@ -27,31 +45,44 @@ func (s *BankService) CreateAccount(ctx context.Context, owner string, initialBa
 	return account.ID, nil
 }
 
-func (s *BankService) CreateTransaction(ctx context.Context, accountID string, txnType domain.TransactionType, amount float64) (domain.Transaction, error) {
-	logger := s.logger.With("account_id", accountID, "amount", amount, "transaction_type", txnType)
+func (s *BankService) CreateTransaction(ctx context.Context, accountID string, txnType domain.TransactionType, amount domain.Money, currency domain.Currency) (domain.Transaction, error) {
+	logger := s.logger.With("account_id", accountID, "amount", amount, "currency", currency, "transaction_type", txnType)
 
 	logger.InfoContext(ctx, "Processing transaction")
 
-	account, err := s.repo.GetAccount(ctx, accountID)
-	if err != nil {
-		logger.WarnContext(ctx, "Transaction failed (invalid account)", "reason", err.Error())
-		return domain.Transaction{}, err
-	}
-
 	var transaction domain.Transaction
-	if txnType == domain.Deposit {
-		transaction, err = account.Deposit(amount)
-	} else {
-		transaction, err = account.Withdraw(amount)
-	}
+	err := s.retryOnConflict(ctx, logger, func() error {
+		account, err := s.repo.GetAccount(ctx, accountID)
+		if err != nil {
+			logger.WarnContext(ctx, "Transaction failed (invalid account)", "reason", err.Error())
+			return err
+		}
 
-	if err != nil {
-		logger.WarnContext(ctx, "Transaction denied", "reason", err.Error())
-		return domain.Transaction{}, err
-	}
+		if txnType == domain.Deposit {
+			transaction, err = account.Deposit(amount, currency)
+		} else {
+			transaction, err = account.Withdraw(amount, currency)
+		}
+
+		if err != nil {
+			logger.WarnContext(ctx, "Transaction denied", "reason", err.Error())
+			return err
+		}
 
-	if err := s.repo.Record(ctx, account, transaction); err != nil {
-		logger.ErrorContext(ctx, "Failed to record transaction", "error", err.Error())
+		changes := ports.Changeset{
+			Accounts:     []domain.Account{account},
+			Transactions: []domain.Transaction{transaction},
+			Entries:      domain.PostTransaction(transaction, domain.LedgerCash),
+		}
+		if err := s.repo.Commit(ctx, changes); err != nil {
+			if !errors.Is(err, domain.ErrVersionConflict) {
+				logger.ErrorContext(ctx, "Failed to record transaction", "error", err.Error())
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return domain.Transaction{}, err
 	}
 
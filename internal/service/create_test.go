@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 
 	"github.com/hesampakdaman/banking-service/internal/domain"
@@ -14,7 +15,7 @@ func TestBankService_CreateAccount(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: A valid account request
-	accountID, err := service.CreateAccount(ctx, "foo", 1000)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: We retrieve the created account
@@ -22,7 +23,7 @@ func TestBankService_CreateAccount(t *testing.T) {
 	assert.NilError(t, err)
 
 	// Then: The account should exist with correct balance
-	expected, _ := domain.NewAccount(accountID, "foo", 1000)
+	expected, _ := domain.NewAccount(accountID, "foo", "USD", domain.MustParseMoney("1000"))
 	assert.DeepEqual(t, expected, account)
 }
 
@@ -31,7 +32,7 @@ func TestBankService_CreateAccount_NegativeBalance(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An attempt to create an account with a negative balance
-	_, err := service.CreateAccount(ctx, "foo", -100)
+	_, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("-100"), domain.Money{})
 
 	// Then: It should fail with ErrNegativeBalance
 	assert.Assert(t, errors.Is(err, domain.ErrNegativeBalance))
@@ -42,7 +43,7 @@ func TestBankService_CreateAccount_MissingOwner(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An attempt to create an account with an empty owner
-	_, err := service.CreateAccount(ctx, "", 500)
+	_, err := service.CreateAccount(ctx, "", "USD", domain.MustParseMoney("500"), domain.Money{})
 
 	// Then: It should fail with ErrInvalidOwner
 	assert.Assert(t, errors.Is(err, domain.ErrInvalidOwner))
@@ -58,11 +59,11 @@ func TestBankService_CreateAccount_Duplicate(t *testing.T) {
 	defer func() { domain.GetUUID = originalUUID }()
 
 	// Given: A valid account is created
-	_, err := service.CreateAccount(ctx, "foo", 1000)
+	_, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Trying to create another account (which will get the same "fixed-uuid")
-	_, err = service.CreateAccount(ctx, "bar", 500)
+	_, err = service.CreateAccount(ctx, "bar", "USD", domain.MustParseMoney("500"), domain.Money{})
 
 	// Then: It should fail with ErrAccountAlreadyExists
 	assert.Assert(t, errors.Is(err, domain.ErrAccountAlreadyExists))
@@ -73,17 +74,17 @@ func TestBankService_Withdraw(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An account with sufficient balance
-	accountID, err := service.CreateAccount(ctx, "foo", 1000)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: A valid withdrawal is made
-	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, 200)
+	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("200"), "USD")
 	assert.NilError(t, err)
 
 	// Then: The balance should be updated
 	account, err := service.GetAccount(ctx, accountID)
 	assert.NilError(t, err)
-	assert.Equal(t, account.Balance, 800.0)
+	assert.Equal(t, account.Balance, domain.MustParseMoney("800.00"))
 }
 
 func TestBankService_Withdraw_NonExistentAccount(t *testing.T) {
@@ -91,7 +92,7 @@ func TestBankService_Withdraw_NonExistentAccount(t *testing.T) {
 	ctx := context.Background()
 
 	// When: Trying to withdraw from a non-existent account
-	_, err := service.CreateTransaction(ctx, "non-existent-id", domain.Withdrawal, 100)
+	_, err := service.CreateTransaction(ctx, "non-existent-id", domain.Withdrawal, domain.MustParseMoney("100"), "USD")
 
 	// Then: Should return an error
 	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID))
@@ -102,11 +103,11 @@ func TestBankService_Withdraw_NegativeAmount(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An existing account
-	accountID, err := service.CreateAccount(ctx, "foo", 1000)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Trying to withdraw a negative amount
-	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, -100)
+	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("-100"), "USD")
 
 	// Then: Should return an error
 	assert.Assert(t, errors.Is(err, domain.ErrInvalidAmount))
@@ -117,11 +118,11 @@ func TestBankService_Withdraw_ZeroAmount(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An existing account
-	accountID, err := service.CreateAccount(ctx, "foo", 1000)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Trying to withdraw zero
-	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, 0)
+	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("0"), "USD")
 
 	// Then: Should return an error
 	assert.Assert(t, errors.Is(err, domain.ErrInvalidAmount))
@@ -132,11 +133,11 @@ func TestBankService_Withdraw_InsufficientFunds(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An account with limited funds
-	accountID, err := service.CreateAccount(ctx, "foo", 100)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("100"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Trying to withdraw more than available balance
-	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, 500)
+	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("500"), "USD")
 
 	// Then: Should return an error
 	assert.Assert(t, errors.Is(err, domain.ErrInsufficientFunds))
@@ -147,17 +148,17 @@ func TestBankService_Deposit(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An account with an initial balance
-	accountID, err := service.CreateAccount(ctx, "foo", 1000)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: A valid deposit is made
-	_, err = service.CreateTransaction(ctx, accountID, domain.Deposit, 500)
+	_, err = service.CreateTransaction(ctx, accountID, domain.Deposit, domain.MustParseMoney("500"), "USD")
 	assert.NilError(t, err)
 
 	// Then: The balance should be updated
 	account, err := service.GetAccount(ctx, accountID)
 	assert.NilError(t, err)
-	assert.Equal(t, account.Balance, 1500.0)
+	assert.Equal(t, account.Balance, domain.MustParseMoney("1500.00"))
 }
 
 func TestBankService_Deposit_NonExistentAccount(t *testing.T) {
@@ -165,7 +166,7 @@ func TestBankService_Deposit_NonExistentAccount(t *testing.T) {
 	ctx := context.Background()
 
 	// When: Trying to deposit to a non-existent account
-	_, err := service.CreateTransaction(ctx, "non-existent-id", domain.Deposit, 100)
+	_, err := service.CreateTransaction(ctx, "non-existent-id", domain.Deposit, domain.MustParseMoney("100"), "USD")
 
 	// Then: Should return an error
 	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID))
@@ -176,11 +177,11 @@ func TestBankService_Deposit_NegativeAmount(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An existing account
-	accountID, err := service.CreateAccount(ctx, "foo", 1000)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Trying to deposit a negative amount
-	_, err = service.CreateTransaction(ctx, accountID, domain.Deposit, -100)
+	_, err = service.CreateTransaction(ctx, accountID, domain.Deposit, domain.MustParseMoney("-100"), "USD")
 
 	// Then: Should return an error
 	assert.Assert(t, errors.Is(err, domain.ErrInvalidAmount))
@@ -191,12 +192,90 @@ func TestBankService_Deposit_ZeroAmount(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An existing account
-	accountID, err := service.CreateAccount(ctx, "foo", 1000)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Trying to deposit zero
-	_, err = service.CreateTransaction(ctx, accountID, domain.Deposit, 0)
+	_, err = service.CreateTransaction(ctx, accountID, domain.Deposit, domain.MustParseMoney("0"), "USD")
 
 	// Then: Should return an error
 	assert.Assert(t, errors.Is(err, domain.ErrInvalidAmount))
 }
+
+func TestBankService_CreateAccount_InvalidCurrency(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An attempt to create an account with an unknown currency
+	_, err := service.CreateAccount(ctx, "foo", "XYZ", domain.MustParseMoney("100"), domain.Money{})
+
+	// Then: It should fail with ErrInvalidCurrency
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidCurrency))
+}
+
+func TestBankService_CreateAccount_PrecisionExceedsCurrency(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An attempt to create a JPY account with fractional yen
+	_, err := service.CreateAccount(ctx, "foo", "JPY", domain.MustParseMoney("100.5"), domain.Money{})
+
+	// Then: It should fail with ErrAmountPrecision
+	assert.Assert(t, errors.Is(err, domain.ErrAmountPrecision))
+}
+
+func TestBankService_Deposit_CurrencyMismatch(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: A USD account
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Trying to deposit EUR
+	_, err = service.CreateTransaction(ctx, accountID, domain.Deposit, domain.MustParseMoney("100"), "EUR")
+
+	// Then: Should return an error
+	assert.Assert(t, errors.Is(err, domain.ErrCurrencyMismatch))
+}
+
+func TestBankService_ConcurrentWithdrawals(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An account with a balance of 1000
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Many withdrawals of 10 race against each other
+	const workers = 100
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("10"), "USD")
+		}()
+	}
+	wg.Wait()
+
+	// Then: Each withdrawal either succeeded or gave up on a version conflict
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Assert(t, errors.Is(err, domain.ErrVersionConflict), err)
+	}
+	assert.Assert(t, succeeded > 0)
+
+	// And: No update was lost; the balance reflects exactly the successful withdrawals
+	account, err := service.GetAccount(ctx, accountID)
+	assert.NilError(t, err)
+	assert.Equal(t, account.Balance, domain.NewMoney(int64(1000-10*succeeded)*100, 2))
+	transactions, err := service.ListTransactions(ctx, accountID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(transactions), succeeded)
+}
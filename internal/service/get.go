@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 )
 
 func (s *BankService) GetAccount(ctx context.Context, accountID string) (domain.Account, error) {
@@ -21,21 +22,129 @@ func (s *BankService) GetAccount(ctx context.Context, accountID string) (domain.
 	return account, nil
 }
 
-func (s *BankService) ListAccounts(ctx context.Context) []domain.Account {
+func (s *BankService) ListAccounts(ctx context.Context) ([]domain.Account, error) {
 	s.logger.InfoContext(ctx, "Listing all accounts")
 
-	accounts := s.repo.ListAccounts(ctx)
+	accounts, err := s.repo.ListAccounts(ctx)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to list accounts", "reason", err.Error())
+		return nil, err
+	}
 
 	s.logger.InfoContext(ctx, "Successfully listed accounts", "count", len(accounts))
-	return accounts
+	return accounts, nil
 }
 
-func (s *BankService) ListTransactions(ctx context.Context, accountID string) []domain.Transaction {
+// QueryAccounts returns one page of the accounts matching the query.
+func (s *BankService) QueryAccounts(ctx context.Context, query ports.AccountQuery) (ports.AccountPage, error) {
+	s.logger.InfoContext(ctx, "Querying accounts")
+
+	query.Limit = pageSize(query.Limit)
+	page, err := s.repo.QueryAccounts(ctx, query)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to query accounts", "reason", err.Error())
+		return ports.AccountPage{}, err
+	}
+
+	s.logger.InfoContext(ctx, "Successfully queried accounts", "count", len(page.Accounts), "total", page.Total)
+	return page, nil
+}
+
+func (s *BankService) ListTransactions(ctx context.Context, accountID string) ([]domain.Transaction, error) {
 	logger := s.logger.With("account_id", accountID)
 	logger.InfoContext(ctx, "Listing all transactions for account")
 
-	transactions := s.repo.ListTransactions(ctx, accountID)
+	transactions, err := s.repo.ListTransactions(ctx, accountID)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to list transactions for account", "reason", err.Error())
+		return nil, err
+	}
 
 	logger.InfoContext(ctx, "Successfully listed transactions for account", "count", len(transactions))
-	return transactions
+	return transactions, nil
+}
+
+// QueryTransactions returns one page of an account's transactions.
+func (s *BankService) QueryTransactions(ctx context.Context, query ports.TransactionQuery) (ports.TransactionPage, error) {
+	logger := s.logger.With("account_id", query.AccountID)
+	logger.InfoContext(ctx, "Querying transactions for account")
+
+	query.Limit = pageSize(query.Limit)
+	page, err := s.repo.QueryTransactions(ctx, query)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to query transactions", "reason", err.Error())
+		return ports.TransactionPage{}, err
+	}
+
+	logger.InfoContext(ctx, "Successfully queried transactions for account", "count", len(page.Transactions))
+	return page, nil
+}
+
+func (s *BankService) GetTransfer(ctx context.Context, transferID string) (domain.Transfer, error) {
+	logger := s.logger.With("transfer_id", transferID)
+
+	logger.InfoContext(ctx, "Retrieving transfer")
+
+	transfer, err := s.repo.GetTransfer(ctx, transferID)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to retrieve transfer", "reason", err.Error())
+		return domain.Transfer{}, err
+	}
+
+	logger.InfoContext(ctx, "Successfully retrieved transfer")
+	return transfer, nil
+}
+
+// GetTransferApproval returns an approval, pending or decided.
+func (s *BankService) GetTransferApproval(ctx context.Context, approvalID string) (domain.TransferApproval, error) {
+	logger := s.logger.With("approval_id", approvalID)
+
+	logger.InfoContext(ctx, "Retrieving transfer approval")
+
+	approval, err := s.repo.GetTransferApproval(ctx, approvalID)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to retrieve transfer approval", "reason", err.Error())
+		return domain.TransferApproval{}, err
+	}
+
+	logger.InfoContext(ctx, "Successfully retrieved transfer approval")
+	return approval, nil
+}
+
+// ListTransferApprovals returns the approvals with the given status, or all
+// of them if status is empty, oldest first.
+func (s *BankService) ListTransferApprovals(ctx context.Context, status domain.ApprovalStatus) ([]domain.TransferApproval, error) {
+	logger := s.logger.With("status", status)
+
+	logger.InfoContext(ctx, "Listing transfer approvals")
+
+	if status != "" {
+		if err := status.Validate(); err != nil {
+			logger.WarnContext(ctx, "Failed to list transfer approvals", "reason", err.Error())
+			return nil, err
+		}
+	}
+
+	approvals, err := s.repo.ListTransferApprovals(ctx, status)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to list transfer approvals", "reason", err.Error())
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Successfully listed transfer approvals", "count", len(approvals))
+	return approvals, nil
+}
+
+// pageSize returns the page size to query for a requested limit: a
+// non-positive limit selects ports.DefaultPageSize and larger limits are
+// capped at ports.MaxPageSize.
+func pageSize(limit int) int {
+	switch {
+	case limit <= 0:
+		return ports.DefaultPageSize
+	case limit > ports.MaxPageSize:
+		return ports.MaxPageSize
+	default:
+		return limit
+	}
 }
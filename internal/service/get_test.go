@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"slices"
 	"testing"
 
 	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 	"gotest.tools/assert"
 )
 
@@ -14,21 +16,22 @@ func TestBankService_ListAccounts(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: Multiple accounts exist
-	account1, err := service.CreateAccount(ctx, "Foo", 1000)
+	account1, err := service.CreateAccount(ctx, "Foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
-	account2, err := service.CreateAccount(ctx, "Bar", 500)
+	account2, err := service.CreateAccount(ctx, "Bar", "USD", domain.MustParseMoney("500"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Listing accounts
-	accounts := service.ListAccounts(ctx)
+	accounts, err := service.ListAccounts(ctx)
+	assert.NilError(t, err)
 
 	// Then: Both accounts should be returned
 	assert.Equal(t, len(accounts), 2)
 
 	// And: Accounts should be correct
-	expectedFoo, _ := domain.NewAccount(account1, "Foo", 1000)
-	expectedBar, _ := domain.NewAccount(account2, "Bar", 500)
+	expectedFoo, _ := domain.NewAccount(account1, "Foo", "USD", domain.MustParseMoney("1000"))
+	expectedBar, _ := domain.NewAccount(account2, "Bar", "USD", domain.MustParseMoney("500"))
 
 	assert.Assert(t, slices.Contains(accounts, expectedFoo))
 	assert.Assert(t, slices.Contains(accounts, expectedBar))
@@ -39,15 +42,124 @@ func TestBankService_ListTransactions(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: An account with deposits and withdrawals
-	accountID, err := service.CreateAccount(ctx, "foo", 1000)
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
-	depositTxn, _ := service.CreateTransaction(ctx, accountID, domain.Deposit, 200)
-	withdrawTxn, _ := service.CreateTransaction(ctx, accountID, domain.Withdrawal, 100)
+	depositTxn, _ := service.CreateTransaction(ctx, accountID, domain.Deposit, domain.MustParseMoney("200"), "USD")
+	withdrawTxn, _ := service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("100"), "USD")
 
 	// When: Listing transactions
-	transactions := service.ListTransactions(ctx, accountID)
+	transactions, err := service.ListTransactions(ctx, accountID)
+	assert.NilError(t, err)
 
 	// Then: The transactions should be recorded correctly
 	assert.DeepEqual(t, transactions, []domain.Transaction{depositTxn, withdrawTxn})
 }
+
+func TestBankService_QueryTransactions(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An account with more transactions than the maximum page size
+	accountID, err := service.CreateAccount(ctx, "foo", "USD", domain.MustParseMoney("0"), domain.Money{})
+	assert.NilError(t, err)
+	for range ports.MaxPageSize + 1 {
+		_, err := service.CreateTransaction(ctx, accountID, domain.Deposit, domain.MustParseMoney("1"), "USD")
+		assert.NilError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected int
+	}{
+		{name: "Default page size", limit: 0, expected: ports.DefaultPageSize},
+		{name: "Requested page size", limit: 10, expected: 10},
+		{name: "Capped page size", limit: ports.MaxPageSize + 1, expected: ports.MaxPageSize},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Querying a page
+			page, err := service.QueryTransactions(ctx, ports.TransactionQuery{AccountID: accountID, Limit: tc.limit})
+
+			// Then: The page size should be bounded
+			assert.NilError(t, err)
+			assert.Equal(t, len(page.Transactions), tc.expected)
+			assert.Assert(t, page.NextCursor != "")
+		})
+	}
+}
+
+func TestBankService_QueryTransactionsForNonExistentAccount(t *testing.T) {
+	service := fixture()
+
+	// When: Querying transactions of an account that does not exist
+	_, err := service.QueryTransactions(context.Background(), ports.TransactionQuery{AccountID: "non-existent-id"})
+
+	// Then: It should fail with ErrInvalidAccountID
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID))
+}
+
+func TestBankService_ReadsHonourCancelledContext(t *testing.T) {
+	service := fixture()
+
+	// Given: An account
+	accountID, err := service.CreateAccount(context.Background(), "foo", "USD", domain.MustParseMoney("100"), domain.Money{})
+	assert.NilError(t, err)
+
+	// And: A cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reads := map[string]func() error{
+		"GetAccount": func() error {
+			_, err := service.GetAccount(ctx, accountID)
+			return err
+		},
+		"ListAccounts": func() error {
+			_, err := service.ListAccounts(ctx)
+			return err
+		},
+		"QueryAccounts": func() error {
+			_, err := service.QueryAccounts(ctx, ports.AccountQuery{})
+			return err
+		},
+		"ListTransactions": func() error {
+			_, err := service.ListTransactions(ctx, accountID)
+			return err
+		},
+		"QueryTransactions": func() error {
+			_, err := service.QueryTransactions(ctx, ports.TransactionQuery{AccountID: accountID})
+			return err
+		},
+		"ListAuditEvents": func() error {
+			_, err := service.ListAuditEvents(ctx, accountID)
+			return err
+		},
+		"CheckLedger": func() error {
+			_, err := service.CheckLedger(ctx)
+			return err
+		},
+	}
+
+	for name, read := range reads {
+		t.Run(name, func(t *testing.T) {
+			// When: Reading with it
+			err := read()
+
+			// Then: It should fail with the context's error
+			assert.Assert(t, errors.Is(err, context.Canceled), "got %v", err)
+		})
+	}
+}
+
+func TestBankService_ListTransactionsForNonExistentAccount(t *testing.T) {
+	service := fixture()
+
+	// When: Listing transactions of an account that does not exist
+	_, err := service.ListTransactions(context.Background(), "non-existent-id")
+
+	// Then: It should fail with ErrInvalidAccountID
+	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID))
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+)
+
+func (s *BankService) CheckLedger(ctx context.Context) (domain.LedgerCheck, error) {
+	s.logger.InfoContext(ctx, "Checking ledger invariants")
+
+	snapshot, err := s.repo.LedgerSnapshot(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to read ledger", "error", err.Error())
+		return domain.LedgerCheck{}, err
+	}
+
+	check, err := domain.CheckLedger(snapshot.Accounts, snapshot.Entries)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to check ledger", "error", err.Error())
+		return domain.LedgerCheck{}, err
+	}
+
+	if !check.Balanced {
+		s.logger.ErrorContext(ctx, "Ledger is out of balance", "totals", check.Totals, "mismatches", len(check.Mismatches))
+		return check, nil
+	}
+
+	s.logger.InfoContext(ctx, "Ledger is balanced", "entries", check.EntryCount)
+	return check, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+func TestBankService_CheckLedger(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: Accounts with an opening balance, deposits, withdrawals and transfers
+	aliceID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+	bobID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("0"), domain.Money{})
+	assert.NilError(t, err)
+	carolID, err := service.CreateAccount(ctx, "Carol", "EUR", domain.MustParseMoney("50"), domain.Money{})
+	assert.NilError(t, err)
+
+	_, err = service.CreateTransaction(ctx, aliceID, domain.Deposit, domain.MustParseMoney("250.50"), "USD")
+	assert.NilError(t, err)
+	_, err = service.CreateTransaction(ctx, aliceID, domain.Withdrawal, domain.MustParseMoney("100"), "USD")
+	assert.NilError(t, err)
+	_, err = service.Transfer(ctx, aliceID, bobID, domain.MustParseMoney("300"), "USD")
+	assert.NilError(t, err)
+	_, err = service.Transfer(ctx, aliceID, carolID, domain.MustParseMoney("10.01"), "USD")
+	assert.NilError(t, err)
+
+	// When: Checking the ledger
+	check, err := service.CheckLedger(ctx)
+	assert.NilError(t, err)
+
+	// Then: Every currency nets to zero and balances agree with the entries
+	assert.Assert(t, check.Balanced, "%+v", check)
+	assert.Equal(t, len(check.Mismatches), 0)
+	assert.Assert(t, check.Totals["USD"].IsZero())
+	assert.Assert(t, check.Totals["EUR"].IsZero())
+
+	// And: Two openings, two transactions and two two-legged transfers were posted
+	assert.Equal(t, check.EntryCount, 2*2+2*2+2*4)
+}
+
+func TestBankService_CheckLedger_DetectsUnpostedChange(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An account whose balance is changed without a ledger posting
+	accountID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("100"), domain.Money{})
+	assert.NilError(t, err)
+
+	account, err := service.repo.GetAccount(ctx, accountID)
+	assert.NilError(t, err)
+	txn, err := account.Deposit(domain.MustParseMoney("5"), "USD")
+	assert.NilError(t, err)
+	assert.NilError(t, service.repo.Record(ctx, account, txn))
+
+	// When: Checking the ledger
+	check, err := service.CheckLedger(ctx)
+	assert.NilError(t, err)
+
+	// Then: The account is reported as disagreeing with the ledger
+	assert.Assert(t, !check.Balanced)
+	assert.DeepEqual(t, check.Mismatches, []domain.BalanceMismatch{{
+		AccountID:     accountID,
+		Balance:       domain.MustParseMoney("105"),
+		LedgerBalance: domain.MustParseMoney("100"),
+	}})
+}
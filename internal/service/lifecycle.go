@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+// FreezeAccount stops an account from sending funds.
+func (s *BankService) FreezeAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	return s.changeStatus(ctx, accountID, "freeze", (*domain.Account).Freeze)
+}
+
+// UnfreezeAccount lets a frozen account send funds again.
+func (s *BankService) UnfreezeAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	return s.changeStatus(ctx, accountID, "unfreeze", (*domain.Account).Unfreeze)
+}
+
+// CloseAccount permanently closes an account with a zero balance.
+func (s *BankService) CloseAccount(ctx context.Context, accountID string) (domain.Account, error) {
+	return s.changeStatus(ctx, accountID, "close", (*domain.Account).Close)
+}
+
+// changeStatus applies a lifecycle change to an account and commits it,
+// returning the account as stored.
+func (s *BankService) changeStatus(ctx context.Context, accountID, action string, change func(*domain.Account) error) (domain.Account, error) {
+	logger := s.logger.With("account_id", accountID, "action", action)
+
+	logger.InfoContext(ctx, "Changing account status")
+
+	var account domain.Account
+	err := s.retryOnConflict(ctx, logger, func() error {
+		var err error
+		account, err = s.repo.GetAccount(ctx, accountID)
+		if err != nil {
+			logger.WarnContext(ctx, "Status change failed (invalid account)", "reason", err.Error())
+			return err
+		}
+
+		if err := change(&account); err != nil {
+			logger.WarnContext(ctx, "Status change denied", "status", account.Status, "reason", err.Error())
+			return err
+		}
+
+		if err := s.repo.Commit(ctx, ports.Changeset{Accounts: []domain.Account{account}}); err != nil {
+			if !errors.Is(err, domain.ErrVersionConflict) {
+				logger.ErrorContext(ctx, "Failed to change account status", "error", err.Error())
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	// Commit has incremented the stored version
+	account.Version++
+
+	logger.InfoContext(ctx, "Account status changed", "status", account.Status)
+	return account, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+func TestBankService_FreezeAccount(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: Two active accounts
+	frozenID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	otherID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("500"), domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Freezing one of them
+	account, err := service.FreezeAccount(ctx, frozenID)
+	assert.NilError(t, err)
+
+	// Then: The stored account should be frozen
+	assert.Equal(t, account.Status, domain.StatusFrozen)
+	stored, err := service.GetAccount(ctx, frozenID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stored, account)
+
+	// And: It should not be able to send funds
+	_, err = service.CreateTransaction(ctx, frozenID, domain.Withdrawal, domain.MustParseMoney("100"), "USD")
+	assert.Assert(t, errors.Is(err, domain.ErrAccountFrozen))
+
+	_, err = service.Transfer(ctx, frozenID, otherID, domain.MustParseMoney("100"), "USD")
+	assert.Assert(t, errors.Is(err, domain.ErrAccountFrozen))
+
+	// But: It should still be able to receive funds
+	_, err = service.CreateTransaction(ctx, frozenID, domain.Deposit, domain.MustParseMoney("100"), "USD")
+	assert.NilError(t, err)
+
+	_, err = service.Transfer(ctx, otherID, frozenID, domain.MustParseMoney("100"), "USD")
+	assert.NilError(t, err)
+
+	// When: Unfreezing it
+	account, err = service.UnfreezeAccount(ctx, frozenID)
+	assert.NilError(t, err)
+
+	// Then: It should be able to send funds again
+	assert.Equal(t, account.Status, domain.StatusActive)
+	_, err = service.CreateTransaction(ctx, frozenID, domain.Withdrawal, domain.MustParseMoney("100"), "USD")
+	assert.NilError(t, err)
+}
+
+func TestBankService_CloseAccount(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An account whose balance has been withdrawn
+	accountID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("100"), domain.Money{})
+	assert.NilError(t, err)
+
+	otherID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("500"), domain.Money{})
+	assert.NilError(t, err)
+
+	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("100"), "USD")
+	assert.NilError(t, err)
+
+	// When: Closing it
+	account, err := service.CloseAccount(ctx, accountID)
+	assert.NilError(t, err)
+
+	// Then: The account should be closed
+	assert.Equal(t, account.Status, domain.StatusClosed)
+
+	// And: It should reject all further operations
+	_, err = service.CreateTransaction(ctx, accountID, domain.Deposit, domain.MustParseMoney("100"), "USD")
+	assert.Assert(t, errors.Is(err, domain.ErrAccountClosed))
+
+	_, err = service.Transfer(ctx, otherID, accountID, domain.MustParseMoney("100"), "USD")
+	assert.Assert(t, errors.Is(err, domain.ErrAccountClosed))
+
+	_, err = service.FreezeAccount(ctx, accountID)
+	assert.Assert(t, errors.Is(err, domain.ErrAccountClosed))
+
+	_, err = service.CloseAccount(ctx, accountID)
+	assert.Assert(t, errors.Is(err, domain.ErrAccountClosed))
+}
+
+func TestBankService_ChangeStatus_Errors(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An active account with a balance
+	accountID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("100"), domain.Money{})
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name      string
+		accountID string
+		change    func(context.Context, string) (domain.Account, error)
+		wantErr   error
+	}{
+		{
+			name:      "Close with non-zero balance",
+			accountID: accountID,
+			change:    service.CloseAccount,
+			wantErr:   domain.ErrAccountNotEmpty,
+		},
+		{
+			name:      "Unfreeze an active account",
+			accountID: accountID,
+			change:    service.UnfreezeAccount,
+			wantErr:   domain.ErrInvalidStatusTransition,
+		},
+		{
+			name:      "Freeze a non-existent account",
+			accountID: "non-existent-id",
+			change:    service.FreezeAccount,
+			wantErr:   domain.ErrInvalidAccountID,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Attempting the status change
+			_, err := tc.change(ctx, tc.accountID)
+
+			// Then: Should return the expected error
+			assert.Assert(t, errors.Is(err, tc.wantErr))
+		})
+	}
+
+	// And: The account should be unchanged
+	account, err := service.GetAccount(ctx, accountID)
+	assert.NilError(t, err)
+	assert.Equal(t, account.Status, domain.StatusActive)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
+)
+
+// SetOverdraftLimit changes an account's overdraft limit and records the
+// change in the audit trail.
+func (s *BankService) SetOverdraftLimit(ctx context.Context, accountID string, limit domain.Money) (domain.Account, error) {
+	logger := s.logger.With("account_id", accountID, "overdraft_limit", limit)
+
+	logger.InfoContext(ctx, "Changing overdraft limit")
+
+	var account domain.Account
+	err := s.retryOnConflict(ctx, logger, func() error {
+		var err error
+		account, err = s.repo.GetAccount(ctx, accountID)
+		if err != nil {
+			logger.WarnContext(ctx, "Overdraft limit change failed (invalid account)", "reason", err.Error())
+			return err
+		}
+
+		event, err := account.SetOverdraftLimit(limit)
+		if err != nil {
+			logger.WarnContext(ctx, "Overdraft limit change denied", "reason", err.Error())
+			return err
+		}
+
+		changes := ports.Changeset{
+			Accounts:    []domain.Account{account},
+			AuditEvents: []domain.AuditEvent{event},
+		}
+		if err := s.repo.Commit(ctx, changes); err != nil {
+			if !errors.Is(err, domain.ErrVersionConflict) {
+				logger.ErrorContext(ctx, "Failed to change overdraft limit", "error", err.Error())
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Account{}, err
+	}
+
+	// Commit has incremented the stored version
+	account.Version++
+
+	logger.InfoContext(ctx, "Overdraft limit changed")
+	return account, nil
+}
+
+// ListAuditEvents returns the recorded changes to an account's settings,
+// oldest first.
+func (s *BankService) ListAuditEvents(ctx context.Context, accountID string) ([]domain.AuditEvent, error) {
+	logger := s.logger.With("account_id", accountID)
+	logger.InfoContext(ctx, "Listing audit events for account")
+
+	events, err := s.repo.ListAuditEvents(ctx, accountID)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to list audit events for account", "reason", err.Error())
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Successfully listed audit events for account", "count", len(events))
+	return events, nil
+}
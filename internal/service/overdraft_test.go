@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hesampakdaman/banking-service/internal/domain"
+	"gotest.tools/assert"
+)
+
+func TestBankService_Withdraw_WithinOverdraft(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An account created with an overdraft limit
+	accountID, err := service.CreateAccount(ctx, "Acme", "USD", domain.MustParseMoney("100"), domain.MustParseMoney("500"))
+	assert.NilError(t, err)
+
+	// When: Withdrawing down to minus the limit
+	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("600"), "USD")
+	assert.NilError(t, err)
+
+	// Then: The balance should be negative
+	account, err := service.GetAccount(ctx, accountID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, account.Balance, domain.MustParseMoney("-500"))
+
+	// And: Going beyond the limit should fail
+	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("0.01"), "USD")
+	assert.Assert(t, errors.Is(err, domain.ErrInsufficientFunds))
+
+	// And: The ledger should still balance
+	check, err := service.CheckLedger(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, check.Balanced)
+}
+
+func TestBankService_Transfer_WithinOverdraft(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: A source account with an overdraft limit
+	fromID, err := service.CreateAccount(ctx, "Acme", "USD", domain.MustParseMoney("0"), domain.MustParseMoney("250"))
+	assert.NilError(t, err)
+
+	toID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("0"), domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Transferring more than the balance but within the limit
+	_, err = service.Transfer(ctx, fromID, toID, domain.MustParseMoney("250"), "USD")
+	assert.NilError(t, err)
+
+	// Then: The source account should be overdrawn
+	from, err := service.GetAccount(ctx, fromID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, from.Balance, domain.MustParseMoney("-250"))
+
+	// And: Transferring beyond the limit should fail
+	_, err = service.Transfer(ctx, fromID, toID, domain.MustParseMoney("1"), "USD")
+	assert.Assert(t, errors.Is(err, domain.ErrInsufficientFunds))
+}
+
+func TestBankService_SetOverdraftLimit(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An account created with an overdraft limit
+	accountID, err := service.CreateAccount(ctx, "Acme", "USD", domain.MustParseMoney("100"), domain.MustParseMoney("500"))
+	assert.NilError(t, err)
+
+	// When: Raising the limit
+	account, err := service.SetOverdraftLimit(ctx, accountID, domain.MustParseMoney("1000"))
+	assert.NilError(t, err)
+
+	// Then: The stored account should have the new limit
+	assert.DeepEqual(t, account.OverdraftLimit, domain.MustParseMoney("1000.00"))
+	stored, err := service.GetAccount(ctx, accountID)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, stored, account)
+
+	// And: Both the initial limit and the change should be audited
+	events, err := service.ListAuditEvents(ctx, accountID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(events), 2)
+
+	assert.Equal(t, events[0].Action, domain.AuditOverdraftLimitChanged)
+	assert.Equal(t, events[0].OldValue, "0.00")
+	assert.Equal(t, events[0].NewValue, "500.00")
+	assert.Equal(t, events[1].OldValue, "500.00")
+	assert.Equal(t, events[1].NewValue, "1000.00")
+}
+
+func TestBankService_SetOverdraftLimit_Errors(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: An account that is overdrawn by 300
+	accountID, err := service.CreateAccount(ctx, "Acme", "USD", domain.MustParseMoney("0"), domain.MustParseMoney("500"))
+	assert.NilError(t, err)
+
+	_, err = service.CreateTransaction(ctx, accountID, domain.Withdrawal, domain.MustParseMoney("300"), "USD")
+	assert.NilError(t, err)
+
+	tests := []struct {
+		name      string
+		accountID string
+		limit     domain.Money
+		wantErr   error
+	}{
+		{
+			name:      "Limit below the overdraft in use",
+			accountID: accountID,
+			limit:     domain.MustParseMoney("299.99"),
+			wantErr:   domain.ErrOverdraftInUse,
+		},
+		{
+			name:      "Negative limit",
+			accountID: accountID,
+			limit:     domain.MustParseMoney("-100"),
+			wantErr:   domain.ErrInvalidOverdraftLimit,
+		},
+		{
+			name:      "Too many decimal places",
+			accountID: accountID,
+			limit:     domain.MustParseMoney("400.001"),
+			wantErr:   domain.ErrAmountPrecision,
+		},
+		{
+			name:      "Non-existent account",
+			accountID: "non-existent-id",
+			limit:     domain.MustParseMoney("100"),
+			wantErr:   domain.ErrInvalidAccountID,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: Changing the overdraft limit
+			_, err := service.SetOverdraftLimit(ctx, tc.accountID, tc.limit)
+
+			// Then: Should return the expected error
+			assert.Assert(t, errors.Is(err, tc.wantErr))
+		})
+	}
+
+	// And: Only the initial limit should be audited
+	events, err := service.ListAuditEvents(ctx, accountID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(events), 1)
+}
@@ -1,18 +1,58 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"math/rand/v2"
+	"time"
 
+	"github.com/hesampakdaman/banking-service/internal/domain"
 	"github.com/hesampakdaman/banking-service/internal/ports"
 )
 
+// maxCommitAttempts bounds how often an operation is retried after losing
+// an optimistic concurrency race on one of its accounts.
+const maxCommitAttempts = 5
+
 // BankService provides business logic for accounts and transactions.
 type BankService struct {
-	repo   ports.Repository
-	logger *slog.Logger
+	repo  ports.Repository
+	rates ports.ExchangeRateProvider
+	// approvalThreshold is the amount above which a transfer needs approval
+	approvalThreshold domain.ApprovalThreshold
+	logger            *slog.Logger
 }
 
-func NewBankService(repo ports.Repository, logger *slog.Logger) *BankService {
+// NewBankService returns a BankService that holds transfers of more than
+// approvalThreshold for approval. A disabled threshold makes every transfer
+// at once.
+func NewBankService(repo ports.Repository, rates ports.ExchangeRateProvider, approvalThreshold domain.ApprovalThreshold, logger *slog.Logger) *BankService {
 	logger = logger.With("component", "BankService")
-	return &BankService{repo: repo, logger: logger}
+	return &BankService{repo: repo, rates: rates, approvalThreshold: approvalThreshold, logger: logger}
+}
+
+// retryOnConflict runs attempt until it succeeds, fails with an error other
+// than domain.ErrVersionConflict, or maxCommitAttempts is reached. Every
+// attempt must re-read the accounts it modifies.
+func (s *BankService) retryOnConflict(ctx context.Context, logger *slog.Logger, attempt func() error) error {
+	var err error
+	for i := 1; i <= maxCommitAttempts; i++ {
+		if err = attempt(); !errors.Is(err, domain.ErrVersionConflict) {
+			return err
+		}
+
+		logger.DebugContext(ctx, "Concurrent update detected, retrying", "attempt", i)
+
+		// Jittered backoff so that competing writers spread out
+		backoff := time.Duration(rand.Int64N(int64(i) * int64(time.Millisecond)))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+
+	logger.WarnContext(ctx, "Giving up after repeated concurrent updates", "attempts", maxCommitAttempts)
+	return err
 }
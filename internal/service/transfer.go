@@ -2,59 +2,245 @@ package service
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 
 	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 )
 
-func (s *BankService) Transfer(ctx context.Context, fromAccountID, toAccountID string, amount float64) (domain.Transaction, domain.Transaction, error) {
-	logger := s.logger.With("from_account_id", fromAccountID, "to_account_id", toAccountID, "amount", amount)
+// RequestTransfer makes a transfer on behalf of requester. A transfer of
+// more than the approval threshold is not made, but recorded as a pending
+// approval for an operator other than requester to decide on. Exactly one
+// of the returned transfer and approval is set.
+//
+// A transfer that would fail if made now is refused, whether or not it
+// needs approval.
+func (s *BankService) RequestTransfer(ctx context.Context, requester, fromAccountID, toAccountID string, amount domain.Money, currency domain.Currency) (domain.Transfer, domain.TransferApproval, error) {
+	logger := s.logger.With("requested_by", requester, "from_account_id", fromAccountID, "to_account_id", toAccountID, "amount", amount, "currency", currency)
+
+	held, err := s.needsApproval(ctx, amount, currency)
+	if err != nil {
+		logger.WarnContext(ctx, "Transfer failed (approval threshold)", "reason", err.Error())
+		return domain.Transfer{}, domain.TransferApproval{}, err
+	}
+	if !held {
+		transfer, err := s.Transfer(ctx, fromAccountID, toAccountID, amount, currency)
+		return transfer, domain.TransferApproval{}, err
+	}
+
+	logger.InfoContext(ctx, "Requesting approval of transfer", "threshold", s.approvalThreshold.Amount, "threshold_currency", s.approvalThreshold.Currency)
+
+	if _, _, err := s.prepareTransfer(ctx, logger, fromAccountID, toAccountID, amount, currency); err != nil {
+		return domain.Transfer{}, domain.TransferApproval{}, err
+	}
+
+	approval := domain.NewTransferApproval(requester, fromAccountID, toAccountID, amount, currency)
+	logger = logger.With("approval_id", approval.ID)
+	if err := s.repo.Commit(ctx, ports.Changeset{NewApprovals: []domain.TransferApproval{approval}}); err != nil {
+		logger.ErrorContext(ctx, "Failed to record transfer approval", "error", err.Error())
+		return domain.Transfer{}, domain.TransferApproval{}, err
+	}
+
+	logger.InfoContext(ctx, "Transfer awaits approval")
+	return domain.Transfer{}, approval, nil
+}
+
+// needsApproval reports whether a transfer of amount in currency must be
+// approved before it is made. An amount in another currency than the
+// threshold's is converted first; without an exchange rate to do so, the
+// transfer is refused rather than guessed at.
+func (s *BankService) needsApproval(ctx context.Context, amount domain.Money, currency domain.Currency) (bool, error) {
+	if !s.approvalThreshold.Enabled() {
+		return false, nil
+	}
+	if err := currency.Validate(); err != nil {
+		return false, err
+	}
+
+	if currency != s.approvalThreshold.Currency {
+		rate, err := s.rates.Rate(ctx, currency, s.approvalThreshold.Currency)
+		if err != nil {
+			return false, err
+		}
+		if amount, err = rate.Convert(amount); err != nil {
+			return false, err
+		}
+	}
+	return amount.Cmp(s.approvalThreshold.Amount) > 0, nil
+}
+
+// ApproveTransfer records approver's approval of a pending transfer and
+// makes it, atomically. If the transfer cannot be made, for example for
+// lack of funds, it stays pending.
+func (s *BankService) ApproveTransfer(ctx context.Context, approver, approvalID string) (domain.TransferApproval, error) {
+	logger := s.logger.With("approval_id", approvalID, "approver", approver)
+
+	logger.InfoContext(ctx, "Approving transfer")
+
+	var approval domain.TransferApproval
+	err := s.retryOnConflict(ctx, logger, func() error {
+		var err error
+		approval, err = s.decidableApproval(ctx, logger, approvalID, approver)
+		if err != nil {
+			return err
+		}
+
+		transfer, changes, err := s.prepareTransfer(ctx, logger, approval.FromAccountID, approval.ToAccountID, approval.Amount, approval.Currency)
+		if err != nil {
+			return err
+		}
+		if err := approval.Approve(approver, transfer.ID); err != nil {
+			return err
+		}
+
+		changes.Approvals = []domain.TransferApproval{approval}
+		return s.commitTransfer(ctx, logger, changes)
+	})
+	if err != nil {
+		return domain.TransferApproval{}, err
+	}
+
+	// Commit has incremented the stored version
+	approval.Version++
+
+	logger.InfoContext(ctx, "Transfer approved", "transfer_id", approval.TransferID)
+	return approval, nil
+}
+
+// RejectTransfer records approver's rejection of a pending transfer, for
+// reason; the transfer is never made.
+func (s *BankService) RejectTransfer(ctx context.Context, approver, approvalID, reason string) (domain.TransferApproval, error) {
+	logger := s.logger.With("approval_id", approvalID, "approver", approver)
+
+	logger.InfoContext(ctx, "Rejecting transfer")
+
+	var approval domain.TransferApproval
+	err := s.retryOnConflict(ctx, logger, func() error {
+		var err error
+		approval, err = s.decidableApproval(ctx, logger, approvalID, approver)
+		if err != nil {
+			return err
+		}
+		if err := approval.Reject(approver, reason); err != nil {
+			return err
+		}
+
+		if err := s.repo.Commit(ctx, ports.Changeset{Approvals: []domain.TransferApproval{approval}}); err != nil {
+			if !errors.Is(err, domain.ErrVersionConflict) {
+				logger.ErrorContext(ctx, "Failed to reject transfer", "error", err.Error())
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.TransferApproval{}, err
+	}
+
+	// Commit has incremented the stored version
+	approval.Version++
+
+	logger.InfoContext(ctx, "Transfer rejected")
+	return approval, nil
+}
+
+// decidableApproval reads an approval that approver may decide on.
+func (s *BankService) decidableApproval(ctx context.Context, logger *slog.Logger, approvalID, approver string) (domain.TransferApproval, error) {
+	approval, err := s.repo.GetTransferApproval(ctx, approvalID)
+	if err != nil {
+		logger.WarnContext(ctx, "Decision failed (invalid approval)", "reason", err.Error())
+		return domain.TransferApproval{}, err
+	}
+	if err := approval.CheckDecision(approver); err != nil {
+		logger.WarnContext(ctx, "Decision denied", "status", approval.Status, "reason", err.Error())
+		return domain.TransferApproval{}, err
+	}
+	return approval, nil
+}
+
+// Transfer makes a transfer at once, without approval. It is the step
+// RequestTransfer and ApproveTransfer end in.
+func (s *BankService) Transfer(ctx context.Context, fromAccountID, toAccountID string, amount domain.Money, currency domain.Currency) (domain.Transfer, error) {
+	logger := s.logger.With("from_account_id", fromAccountID, "to_account_id", toAccountID, "amount", amount, "currency", currency)
 
 	logger.InfoContext(ctx, "Processing transfer")
 
+	var transfer domain.Transfer
+	err := s.retryOnConflict(ctx, logger, func() error {
+		var changes ports.Changeset
+		var err error
+		transfer, changes, err = s.prepareTransfer(ctx, logger, fromAccountID, toAccountID, amount, currency)
+		if err != nil {
+			return err
+		}
+		return s.commitTransfer(ctx, logger, changes)
+	})
+	if err != nil {
+		return domain.Transfer{}, err
+	}
+
+	logger.InfoContext(ctx, "Transfer successful", "transfer_id", transfer.ID)
+	return transfer, nil
+}
+
+// prepareTransfer reads both accounts and moves the funds between them,
+// returning the changes that record the transfer. Nothing is committed.
+func (s *BankService) prepareTransfer(ctx context.Context, logger *slog.Logger, fromAccountID, toAccountID string, amount domain.Money, currency domain.Currency) (domain.Transfer, ports.Changeset, error) {
 	// Fetch both accounts from repository
 	fromAccount, err := s.repo.GetAccount(ctx, fromAccountID)
 	if err != nil {
 		logger.WarnContext(ctx, "Transfer failed (invalid source account)", "reason", err.Error())
-		return domain.Transaction{}, domain.Transaction{}, err
+		return domain.Transfer{}, ports.Changeset{}, err
 	}
 
 	toAccount, err := s.repo.GetAccount(ctx, toAccountID)
 	if err != nil {
 		logger.WarnContext(ctx, "Transfer failed (invalid destination account)", "reason", err.Error())
-		return domain.Transaction{}, domain.Transaction{}, err
+		return domain.Transfer{}, ports.Changeset{}, err
 	}
 
-	// Attempt transfer
-	fromTxn, toTxn, err := fromAccount.Transfer(&toAccount, amount)
+	// Attempt transfer, converting the amount if the accounts' currencies differ
+	var transfer domain.Transfer
+	if fromAccount.Currency == toAccount.Currency {
+		transfer, err = fromAccount.Transfer(&toAccount, amount, currency)
+	} else {
+		var rate domain.ExchangeRate
+		rate, err = s.rates.Rate(ctx, fromAccount.Currency, toAccount.Currency)
+		if err != nil {
+			logger.WarnContext(ctx, "Transfer failed (exchange rate lookup)", "reason", err.Error())
+			return domain.Transfer{}, ports.Changeset{}, err
+		}
+
+		logger = logger.With("rate", rate.Rate, "rate_timestamp", rate.Timestamp)
+		transfer, err = fromAccount.TransferWithConversion(&toAccount, amount, currency, rate)
+	}
 	if err != nil {
 		logger.WarnContext(ctx, "Transfer denied", "reason", err.Error())
-		return domain.Transaction{}, domain.Transaction{}, err
+		return domain.Transfer{}, ports.Changeset{}, err
 	}
 
-	// Record both transactions, ensuring consistency
-	if err := s.repo.Record(ctx, fromAccount, fromTxn); err != nil {
-		logger.ErrorContext(ctx, "Failed to record source transaction", "error", err.Error())
-		return domain.Transaction{}, domain.Transaction{}, err
+	// Record both legs atomically, so money is never debited without being
+	// credited. Each leg is posted against the suspense account, which nets
+	// to zero per currency once both legs are in.
+	changes := ports.Changeset{
+		Accounts:     []domain.Account{fromAccount, toAccount},
+		Transactions: []domain.Transaction{transfer.Withdrawal, transfer.Deposit},
+		Transfers:    []domain.Transfer{transfer},
+		Entries: append(
+			domain.PostTransaction(transfer.Withdrawal, domain.LedgerSuspense),
+			domain.PostTransaction(transfer.Deposit, domain.LedgerSuspense)...,
+		),
 	}
+	return transfer, changes, nil
+}
 
-	if err := s.repo.Record(ctx, toAccount, toTxn); err != nil {
-		// **Rollback:** Attempt to revert withdrawal
-		logger.ErrorContext(ctx, "Failed to record destination transaction, attempting rollback", "error", err.Error())
-
-		rollbackTxn, rollbackErr := fromAccount.Deposit(amount)
-
-		if rollbackErr != nil {
-			logger.ErrorContext(ctx, "Rollback failed, system may be in an inconsistent state", "rollback_error", rollbackErr.Error())
-		} else {
-			if recErr := s.repo.Record(ctx, fromAccount, rollbackTxn); recErr != nil {
-				logger.ErrorContext(ctx, "Failed to record rollback transaction", "rollback_error", recErr.Error())
-			} else {
-				logger.WarnContext(ctx, "Rollback successful")
-			}
+func (s *BankService) commitTransfer(ctx context.Context, logger *slog.Logger, changes ports.Changeset) error {
+	if err := s.repo.Commit(ctx, changes); err != nil {
+		if !errors.Is(err, domain.ErrVersionConflict) {
+			logger.ErrorContext(ctx, "Failed to record transfer", "error", err.Error())
 		}
-		return domain.Transaction{}, domain.Transaction{}, err
+		return err
 	}
-
-	logger.InfoContext(ctx, "Transfer successful")
-	return fromTxn, toTxn, nil
+	return nil
 }
@@ -3,25 +3,27 @@ package service
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 
 	"github.com/hesampakdaman/banking-service/internal/adapters/storage"
 	"github.com/hesampakdaman/banking-service/internal/domain"
+	"github.com/hesampakdaman/banking-service/internal/ports"
 	"gotest.tools/assert"
 )
 
 // mockRepository wraps MemoryRepository and allows simulating failures
 type mockRepository struct {
 	*storage.MemoryRepository
-	failOnRecord bool
+	failOnCommit bool
 }
 
-// Record overrides the normal Record function to simulate failure
-func (m *mockRepository) Record(ctx context.Context, account domain.Account, txn domain.Transaction) error {
-	if m.failOnRecord {
+// Commit overrides the normal Commit function to simulate failure
+func (m *mockRepository) Commit(ctx context.Context, changes ports.Changeset) error {
+	if m.failOnCommit {
 		return errors.New("simulated transaction failure")
 	}
-	return m.MemoryRepository.Record(ctx, account, txn)
+	return m.MemoryRepository.Commit(ctx, changes)
 }
 
 func TestBankService_Transfer(t *testing.T) {
@@ -29,33 +31,35 @@ func TestBankService_Transfer(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: Two accounts exist
-	fromID, err := service.CreateAccount(ctx, "Alice", 1000)
+	fromID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
-	toID, err := service.CreateAccount(ctx, "Bob", 500)
+	toID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("500"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Transferring funds
-	fromTxn, toTxn, err := service.Transfer(ctx, fromID, toID, 200)
+	transfer, err := service.Transfer(ctx, fromID, toID, domain.MustParseMoney("200"), "USD")
 	assert.NilError(t, err)
 
 	// Then: Transactions should be recorded
-	transactions := service.ListTransactions(ctx, fromID)
+	transactions, err := service.ListTransactions(ctx, fromID)
+	assert.NilError(t, err)
 	assert.Equal(t, len(transactions), 1)
-	assert.DeepEqual(t, transactions[0], fromTxn)
+	assert.DeepEqual(t, transactions[0], transfer.Withdrawal)
 
-	transactions = service.ListTransactions(ctx, toID)
+	transactions, err = service.ListTransactions(ctx, toID)
+	assert.NilError(t, err)
 	assert.Equal(t, len(transactions), 1)
-	assert.DeepEqual(t, transactions[0], toTxn)
+	assert.DeepEqual(t, transactions[0], transfer.Deposit)
 
 	// And: Account balances should be updated
 	fromAccount, err := service.GetAccount(ctx, fromID)
 	assert.NilError(t, err)
-	assert.Equal(t, fromAccount.Balance, 800.0)
+	assert.Equal(t, fromAccount.Balance, domain.MustParseMoney("800.00"))
 
 	toAccount, err := service.GetAccount(ctx, toID)
 	assert.NilError(t, err)
-	assert.Equal(t, toAccount.Balance, 700.0)
+	assert.Equal(t, toAccount.Balance, domain.MustParseMoney("700.00"))
 }
 
 func TestBankService_Transfer_InsufficientFunds(t *testing.T) {
@@ -63,14 +67,14 @@ func TestBankService_Transfer_InsufficientFunds(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: Two accounts exist
-	fromID, err := service.CreateAccount(ctx, "Alice", 100)
+	fromID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("100"), domain.Money{})
 	assert.NilError(t, err)
 
-	toID, err := service.CreateAccount(ctx, "Bob", 500)
+	toID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("500"), domain.Money{})
 	assert.NilError(t, err)
 
 	// When: Attempting to transfer more than available balance
-	_, _, err = service.Transfer(ctx, fromID, toID, 200)
+	_, err = service.Transfer(ctx, fromID, toID, domain.MustParseMoney("200"), "USD")
 
 	// Then: Transfer should fail due to insufficient funds
 	assert.Assert(t, errors.Is(err, domain.ErrInsufficientFunds))
@@ -81,48 +85,221 @@ func TestBankService_Transfer_InvalidAccount(t *testing.T) {
 	ctx := context.Background()
 
 	// Given: One valid and one invalid account
-	fromID, err := service.CreateAccount(ctx, "Alice", 1000)
+	fromID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
 	invalidID := "non-existent-id"
 
 	// When: Transferring to a non-existent account
-	_, _, err = service.Transfer(ctx, fromID, invalidID, 100)
+	_, err = service.Transfer(ctx, fromID, invalidID, domain.MustParseMoney("100"), "USD")
 
 	// Then: Transfer should fail
 	assert.Assert(t, errors.Is(err, domain.ErrInvalidAccountID))
 }
 
-func TestBankService_Transfer_Rollback(t *testing.T) {
+func TestBankService_Transfer_CommitFailure(t *testing.T) {
 	service := fixture()
 	ctx := context.Background()
 
 	// Given: Two accounts exist
-	fromID, err := service.CreateAccount(ctx, "Alice", 1000)
+	fromID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
 	assert.NilError(t, err)
 
-	toID, err := service.CreateAccount(ctx, "Bob", 500)
+	toID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("500"), domain.Money{})
 	assert.NilError(t, err)
 
 	// Inject failure in repo
 	service.repo = &mockRepository{
 		MemoryRepository: service.repo.(*storage.MemoryRepository),
-		failOnRecord:     true, // Simulate failure on second transaction
+		failOnCommit:     true,
 	}
 
 	// When: Transferring funds (with failure)
-	_, _, err = service.Transfer(ctx, fromID, toID, 200)
+	_, err = service.Transfer(ctx, fromID, toID, domain.MustParseMoney("200"), "USD")
 
-	// Then: Transfer should fail and rollback should occur
+	// Then: Transfer should fail
 	assert.ErrorContains(t, err, "simulated transaction failure")
 
-	// And: Source account should have its balance restored
+	// And: Neither account balance should have changed
+	fromAccount, err := service.GetAccount(ctx, fromID)
+	assert.NilError(t, err)
+	assert.Equal(t, fromAccount.Balance, domain.MustParseMoney("1000.00"))
+
+	toAccount, err := service.GetAccount(ctx, toID)
+	assert.NilError(t, err)
+	assert.Equal(t, toAccount.Balance, domain.MustParseMoney("500.00"))
+
+	// And: No transaction should have been recorded for either leg
+	fromTxns, err := service.ListTransactions(ctx, fromID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(fromTxns), 0)
+	toTxns, err := service.ListTransactions(ctx, toID)
+	assert.NilError(t, err)
+	assert.Equal(t, len(toTxns), 0)
+}
+
+func TestBankService_Transfer_CurrencyMismatch(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: Accounts in different currencies
+	fromID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	toID, err := service.CreateAccount(ctx, "Bob", "EUR", domain.MustParseMoney("500"), domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Transferring an amount not stated in the source account's currency
+	_, err = service.Transfer(ctx, fromID, toID, domain.MustParseMoney("100"), "EUR")
+
+	// Then: Transfer should fail due to the currency mismatch
+	assert.Assert(t, errors.Is(err, domain.ErrCurrencyMismatch))
+}
+
+func TestBankService_Transfer_CrossCurrency(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: A USD and a EUR account, with a USD→EUR rate of 0.9234
+	fromID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	toID, err := service.CreateAccount(ctx, "Bob", "EUR", domain.MustParseMoney("500"), domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Transferring 10.01 USD
+	transfer, err := service.Transfer(ctx, fromID, toID, domain.MustParseMoney("10.01"), "USD")
+	assert.NilError(t, err)
+
+	// Then: The EUR amount is converted and rounded to cents (9.243234 → 9.24)
 	fromAccount, err := service.GetAccount(ctx, fromID)
 	assert.NilError(t, err)
-	assert.Equal(t, fromAccount.Balance, 1000.0)
+	assert.Equal(t, fromAccount.Balance, domain.MustParseMoney("989.99"))
 
-	// And: Destination account should remain unchanged
 	toAccount, err := service.GetAccount(ctx, toID)
 	assert.NilError(t, err)
-	assert.Equal(t, toAccount.Balance, 500.0)
+	assert.Equal(t, toAccount.Balance, domain.MustParseMoney("509.24"))
+
+	// And: Both legs record the conversion that was applied
+	expected := &domain.Conversion{
+		OriginalAmount:    domain.MustParseMoney("10.01"),
+		OriginalCurrency:  "USD",
+		ConvertedAmount:   domain.MustParseMoney("9.24"),
+		ConvertedCurrency: "EUR",
+		Rate:              domain.MustParseRate("0.9234"),
+		RateTimestamp:     domain.GetTimeNow(),
+	}
+	assert.DeepEqual(t, transfer.Withdrawal.Conversion, expected)
+	assert.DeepEqual(t, transfer.Deposit.Conversion, expected)
+	assert.Equal(t, transfer.Withdrawal.Currency, domain.Currency("USD"))
+	assert.Equal(t, transfer.Deposit.Currency, domain.Currency("EUR"))
+}
+
+func TestBankService_Transfer_NoExchangeRate(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: Accounts in currencies without a quoted rate
+	fromID, err := service.CreateAccount(ctx, "Alice", "EUR", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	toID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("500"), domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Transferring between them
+	_, err = service.Transfer(ctx, fromID, toID, domain.MustParseMoney("100"), "EUR")
+
+	// Then: Transfer should fail as no rate is available
+	assert.Assert(t, errors.Is(err, domain.ErrExchangeRateUnavailable))
+}
+
+func TestBankService_ConcurrentTransfers(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: Two accounts with 1000 each
+	aliceID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	bobID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	// When: Transfers of 10 run concurrently in both directions
+	const workers = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	net := 0 // successful Alice → Bob transfers minus Bob → Alice transfers
+	for i := range 2 * workers {
+		from, to, direction := aliceID, bobID, 1
+		if i%2 == 1 {
+			from, to, direction = bobID, aliceID, -1
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.Transfer(ctx, from, to, domain.MustParseMoney("10"), "USD")
+			if err != nil {
+				assert.Check(t, errors.Is(err, domain.ErrVersionConflict), err)
+				return
+			}
+			mu.Lock()
+			net += direction
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Then: Money is conserved and each balance matches the successful transfers
+	alice, err := service.GetAccount(ctx, aliceID)
+	assert.NilError(t, err)
+	bob, err := service.GetAccount(ctx, bobID)
+	assert.NilError(t, err)
+
+	assert.Equal(t, alice.Balance, domain.NewMoney(int64(1000-10*net)*100, 2))
+	assert.Equal(t, bob.Balance, domain.NewMoney(int64(1000+10*net)*100, 2))
+
+	// And: The ledger agrees with the final balances
+	check, err := service.CheckLedger(ctx)
+	assert.NilError(t, err)
+	assert.Assert(t, check.Balanced, "%+v", check)
+}
+
+func TestBankService_GetTransfer(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// Given: A completed transfer
+	fromID, err := service.CreateAccount(ctx, "Alice", "USD", domain.MustParseMoney("1000"), domain.Money{})
+	assert.NilError(t, err)
+
+	toID, err := service.CreateAccount(ctx, "Bob", "USD", domain.MustParseMoney("500"), domain.Money{})
+	assert.NilError(t, err)
+
+	transfer, err := service.Transfer(ctx, fromID, toID, domain.MustParseMoney("200"), "USD")
+	assert.NilError(t, err)
+
+	// When: Retrieving it by ID
+	actual, err := service.GetTransfer(ctx, transfer.ID)
+	assert.NilError(t, err)
+
+	// Then: Both legs reference the transfer and each other's account
+	assert.DeepEqual(t, actual, transfer)
+	assert.Equal(t, actual.FromAccountID(), fromID)
+	assert.Equal(t, actual.ToAccountID(), toID)
+	assert.Equal(t, actual.Withdrawal.TransferID, transfer.ID)
+	assert.Equal(t, actual.Deposit.TransferID, transfer.ID)
+	assert.Equal(t, actual.Withdrawal.CounterpartyID, toID)
+	assert.Equal(t, actual.Deposit.CounterpartyID, fromID)
+}
+
+func TestBankService_GetTransfer_NotFound(t *testing.T) {
+	service := fixture()
+	ctx := context.Background()
+
+	// When: Retrieving a transfer that does not exist
+	_, err := service.GetTransfer(ctx, "non-existent-id")
+
+	// Then: Should return an error
+	assert.Assert(t, errors.Is(err, domain.ErrTransferNotFound))
 }